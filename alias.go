@@ -0,0 +1,121 @@
+package fixedlength
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+var ErrUnmappedAlias = errors.New("fixedlength: value not in alias map")
+
+// aliasMap holds a registered translation map and its inverse.
+type aliasMap struct {
+	values map[string]string
+	codes  map[string]string
+}
+
+var (
+	aliasesMu sync.RWMutex
+	aliases   = map[string]aliasMap{}
+)
+
+// RegisterAlias makes the code translation map m available to fields
+// tagged with alias=name, e.g.
+//
+//	fixedlength.RegisterAlias("country", map[string]string{"US": "United States"})
+//
+// Such fields are decoded into the value m maps their trimmed content to,
+// and Marshal writes the code of the field's value, the first in
+// alphabetical order if it has several. What happens to codes and values
+// missing from m is set by the aliasUnmapped option: "reject" (the default)
+// fails with ErrUnmappedAlias, and "keep" passes them through unchanged.
+//
+// Registering a name again replaces its map. RegisterAlias panics if m is empty.
+func RegisterAlias(name string, m map[string]string) {
+	if len(m) == 0 {
+		panic("fixedlength: RegisterAlias map is empty")
+	}
+
+	a := aliasMap{
+		values: make(map[string]string, len(m)),
+		codes:  make(map[string]string, len(m)),
+	}
+
+	codes := make([]string, 0, len(m))
+	for code := range m {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	for _, code := range codes {
+		value := m[code]
+		a.values[code] = value
+
+		if _, ok := a.codes[value]; !ok {
+			a.codes[value] = code
+		}
+	}
+
+	aliasesMu.Lock()
+	defer aliasesMu.Unlock()
+
+	aliases[name] = a
+}
+
+// lookupAlias returns the map selected by the alias option, if any.
+func lookupAlias(opts tagOptions) (aliasMap, bool, error) {
+	name, ok := opts["alias"]
+	if !ok || name == "" {
+		return aliasMap{}, false, nil
+	}
+
+	aliasesMu.RLock()
+	defer aliasesMu.RUnlock()
+
+	a, ok := aliases[name]
+	if !ok {
+		return aliasMap{}, false, fmt.Errorf("%w: alias=%s", ErrTagInvalidOption, name)
+	}
+
+	return a, true, nil
+}
+
+// decodeAlias expands a code according to the alias option.
+// Values without an alias are returned unchanged.
+func decodeAlias(code string, opts tagOptions) (string, error) {
+	a, ok, err := lookupAlias(opts)
+	if err != nil || !ok {
+		return code, err
+	}
+
+	return translateAlias(code, a.values, opts)
+}
+
+// encodeAlias returns the code of a value according to the alias option.
+// Values without an alias are returned unchanged.
+func encodeAlias(value string, opts tagOptions) (string, error) {
+	a, ok, err := lookupAlias(opts)
+	if err != nil || !ok {
+		return value, err
+	}
+
+	return translateAlias(value, a.codes, opts)
+}
+
+// translateAlias looks up s in m, handling missing entries according to
+// the aliasUnmapped option.
+func translateAlias(s string, m map[string]string, opts tagOptions) (string, error) {
+	if translated, ok := m[s]; ok {
+		return translated, nil
+	}
+
+	switch mode := opts["aliasUnmapped"]; mode {
+	case "", "reject":
+		return "", fmt.Errorf("%w: %q in alias %s", ErrUnmappedAlias, s, opts["alias"])
+	case "keep":
+		return s, nil
+	default:
+		return "", fmt.Errorf("%w: aliasUnmapped=%s", ErrTagInvalidOption, mode)
+	}
+}
@@ -0,0 +1,104 @@
+package fixedlength
+
+import (
+	"errors"
+	"testing"
+)
+
+func init() {
+	RegisterAlias("testCountry", map[string]string{
+		"US": "United States",
+		"AR": "Argentina",
+		"RA": "Argentina",
+	})
+}
+
+func TestUnmarshalAlias(t *testing.T) {
+	type testStruct struct {
+		Country string `range:"0,2,alias=testCountry"`
+		Origin  string `range:"2,4,alias=testCountry,aliasUnmapped=keep"`
+	}
+
+	var v testStruct
+	if err := Unmarshal([]byte("USXX"), &v); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+
+	if v.Country != "United States" {
+		t.Errorf("Expected v.Country to be 'United States', got %q", v.Country)
+	}
+
+	if v.Origin != "XX" {
+		t.Errorf("Expected v.Origin to be 'XX', got %q", v.Origin)
+	}
+
+	tests := []struct {
+		name    string
+		data    string
+		v       any
+		wantErr error
+	}{
+		{
+			name:    "unmapped code",
+			data:    "XXUS",
+			v:       &testStruct{},
+			wantErr: ErrUnmappedAlias,
+		},
+		{
+			name: "unknown alias",
+			data: "US",
+			v: &struct {
+				Country string `range:"0,2,alias=planet"`
+			}{},
+			wantErr: ErrTagInvalidOption,
+		},
+		{
+			name: "invalid unmapped mode",
+			data: "XX",
+			v: &struct {
+				Country string `range:"0,2,alias=testCountry,aliasUnmapped=drop"`
+			}{},
+			wantErr: ErrTagInvalidOption,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Unmarshal([]byte(tt.data), tt.v); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestMarshalAlias(t *testing.T) {
+	type testStruct struct {
+		Country string `range:"0,2,alias=testCountry"`
+		Origin  string `range:"2,4,alias=testCountry,aliasUnmapped=keep"`
+	}
+
+	// Values with several codes use the first in alphabetical order
+	got, err := Marshal(testStruct{Country: "Argentina", Origin: "XX"})
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	if want := "ARXX"; string(got) != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+
+	_, err = Marshal(testStruct{Country: "Atlantis"})
+	if !errors.Is(err, ErrUnmappedAlias) {
+		t.Errorf("Expected ErrUnmappedAlias, got %v", err)
+	}
+}
+
+func TestRegisterAliasPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Expected RegisterAlias to panic with an empty map")
+		}
+	}()
+
+	RegisterAlias("empty", nil)
+}
@@ -2,8 +2,12 @@ package fixedlength
 
 import (
 	"errors"
+	"fmt"
 	"reflect"
+	"strconv"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 )
 
 // Unmarshaler is the interface implemented by types
@@ -14,6 +18,20 @@ type Unmarshaler interface {
 	Unmarshal([]byte) error
 }
 
+var (
+	ErrInvalidBaseOffset = errors.New("fixedlength: invalid base offset")
+	ErrMaxDepthExceeded  = errors.New("fixedlength: max struct depth exceeded")
+	ErrInvalidDefaults   = errors.New("fixedlength: defaults do not match target type")
+)
+
+// MaxDepth is the maximum depth of nested structs that Unmarshal, Marshal
+// and Fields will recurse into before failing with ErrMaxDepthExceeded.
+//
+// MaxDepth is read without synchronization, so it must only be changed
+// during program initialization, before any decoding or encoding starts
+// (including ScanParallel and Transcode).
+var MaxDepth = 32
+
 var unmarshalerType = reflect.TypeOf((*Unmarshaler)(nil)).Elem()
 
 // implementsUnmarshaler checks if a field implements the Unmarshaler interface
@@ -64,35 +82,435 @@ func Unmarshal(data []byte, v any) error {
 		return InvalidUnmarshalError{reflect.TypeOf(v)}
 	}
 
+	return unmarshalStruct(data, rv.Elem(), reflect.Value{}, "", 0, func(_ string, err error) error {
+		return err
+	})
+}
+
+// UnmarshalWithDefaults is like [Unmarshal] but fields that are blank in data,
+// or that start beyond its end, take the value of the same field in defaults
+// instead of being parsed. defaults must be a struct of the same type v points
+// to, or a pointer to one. Fields present in data are parsed as usual.
+func UnmarshalWithDefaults(data []byte, v, defaults any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return InvalidUnmarshalError{reflect.TypeOf(v)}
+	}
+
+	dv := reflect.ValueOf(defaults)
+	if dv.Kind() == reflect.Pointer && !dv.IsNil() {
+		dv = dv.Elem()
+	}
+	if !dv.IsValid() || dv.Type() != rv.Elem().Type() {
+		return fmt.Errorf("%w: got %v, want %v", ErrInvalidDefaults, reflect.TypeOf(defaults), rv.Elem().Type())
+	}
+
+	return unmarshalStruct(data, rv.Elem(), dv, "", 0, func(_ string, err error) error {
+		return err
+	})
+}
+
+// UnmarshalWithBase is like [Unmarshal] but shifts every field's range by base,
+// which is useful when records are preceded by a prefix of runtime-known length.
+// A field ending in -1 still extends to the end of data.
+func UnmarshalWithBase(data []byte, v any, base int) error {
+	if base < 0 || base > len(data) {
+		return fmt.Errorf("%w: %d", ErrInvalidBaseOffset, base)
+	}
+
+	// Shifting all ranges by base is the same as dropping the prefix
+	return Unmarshal(data[base:], v)
+}
+
+// UnmarshalBestEffort is like [Unmarshal] but decodes every field independently.
+// A field that fails to decode is left at its zero value and its error is
+// recorded in the returned map, keyed by the field name. Fields of nested
+// structs are keyed by their dotted path, e.g. "Nested.A".
+// The returned error is only non-nil when v is not a non-nil pointer or its
+// structs are nested deeper than [MaxDepth].
+func UnmarshalBestEffort(data []byte, v any) (map[string]error, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return nil, InvalidUnmarshalError{reflect.TypeOf(v)}
+	}
+
+	errs := map[string]error{}
+	err := unmarshalStruct(data, rv.Elem(), reflect.Value{}, "", 0, func(name string, err error) error {
+		errs[name] = err
+		return nil
+	})
+
+	return errs, err
+}
+
+// unmarshalStruct parses data into the fields of the struct rv,
+// which is nested depth levels below the value passed to Unmarshal.
+// If defaults is valid, it is a struct of the same type as rv whose
+// fields are used for the fields that are blank in data.
+// Field errors are passed to handleErr along with the field's dotted path.
+// If handleErr returns an error, parsing stops and that error is returned,
+// otherwise the failed field is reset to its zero value and parsing continues.
+func unmarshalStruct(data []byte, rv, defaults reflect.Value, path string, depth int, handleErr func(name string, err error) error) error {
+	if depth > MaxDepth {
+		return fmt.Errorf("%w: %s", ErrMaxDepthExceeded, path)
+	}
+
 	// Iterate over struct fields to map segment names to fields
-	for i := 0; i < rv.Elem().NumField(); i++ {
-		field := rv.Elem().Field(i)
+	for i := 0; i < rv.NumField(); i++ {
+		field := rv.Field(i)
+		structField := rv.Type().Field(i)
+
+		name := structField.Name
+		if path != "" {
+			name = path + "." + name
+		}
+
+		var fieldDefault reflect.Value
+		if defaults.IsValid() {
+			fieldDefault = defaults.Field(i)
+		}
 
 		// Recursively parse the struct
 		if field.Kind() == reflect.Struct && !implementsUnmarshaler(field) {
-			if err := Unmarshal(data, field.Addr().Interface()); err != nil {
+			if err := unmarshalStruct(data, field, fieldDefault, name, depth+1, handleErr); err != nil {
 				return err
 			}
 
 			continue
 		}
 
-		tag := rv.Elem().Type().Field(i).Tag.Get("range")
+		tag := structField.Tag.Get("range")
+		if tag == lineNoTag {
+			continue
+		}
 
-		start, end, err := parseTag(tag, len(data))
+		if fieldDefault.IsValid() && isBlankField(data, tag) {
+			field.Set(fieldDefault)
+			continue
+		}
+
+		err := unmarshalField(data, field, name, tag)
 		if errors.Is(err, ErrTagEmpty) {
 			continue
 		}
 		if err != nil {
-			return err
+			if err := handleErr(name, err); err != nil {
+				return err
+			}
+
+			field.SetZero()
+		}
+	}
+
+	return nil
+}
+
+// isBlankField reports whether the segment of data described by tag is
+// blank once trimmed, or starts beyond the end of data.
+func isBlankField(data []byte, tag string) bool {
+	if x, _, err := parseRange(tag); err == nil && x >= len(data) {
+		return true
+	}
+
+	start, end, err := parseTag(tag, len(data))
+	if err != nil {
+		return false
+	}
+
+	value, err := trimValue(string(data[start:end]), parseTagOptions(tag), false)
+	return err == nil && value == ""
+}
+
+// unmarshalField parses the segment of data described by tag into field.
+// name is the field's dotted path and is used to give context to errors.
+func unmarshalField(data []byte, field reflect.Value, name, tag string) error {
+	start, end, err := parseTag(tag, len(data))
+	if err != nil {
+		return err
+	}
+
+	opts := parseTagOptions(tag)
+
+	if field.Kind() == reflect.String && opts["invalidUTF8"] == "reject" {
+		if err := checkRuneBoundaries(data, start, end); err != nil {
+			return fmt.Errorf("%w: field %s", err, name)
 		}
+	}
 
-		value := strings.TrimSpace(string(data[start:end]))
+	raw := string(data[start:end])
 
-		if err := setFieldValue(field, value); err != nil {
+	// Binary formats such as comp3 are decoded untrimmed,
+	// since their bytes can look like padding
+	value := raw
+	if !isBinaryFormat(opts) {
+		value, err = trimValue(raw, opts, isNumericKind(field.Kind()))
+		if err != nil {
 			return err
 		}
 	}
 
+	// A numeric field made only of zero padding, e.g. "00000" with
+	// trimSet=0, is zero rather than an empty value
+	if value == "" && isNumericKind(field.Kind()) && strings.Contains(raw, "0") {
+		value = "0"
+	}
+
+	if symbols, ok := opts["currency"]; ok {
+		value = stripCurrency(value, symbols)
+	}
+
+	value, err = decodeFormat(value, opts)
+	if err != nil {
+		return fmt.Errorf("%w: field %s", err, name)
+	}
+
+	value, err = decodeAlias(value, opts)
+	if err != nil {
+		return fmt.Errorf("%w: field %s", err, name)
+	}
+
+	// All-zero values are treated as missing when zeroAsNull is set
+	if _, ok := opts["zeroAsNull"]; ok && isAllZeros(value) {
+		field.SetZero()
+		return nil
+	}
+
+	if field.Kind() == reflect.String {
+		value, err = applyInvalidUTF8(value, opts)
+		if err != nil {
+			return fmt.Errorf("%w: field %s", err, name)
+		}
+
+		value, err = applyControls(value, opts)
+		if err != nil {
+			return fmt.Errorf("%w: field %s", err, name)
+		}
+
+		value, err = applyStorePad(value, opts)
+		if err != nil {
+			return fmt.Errorf("%w: field %s", err, name)
+		}
+	}
+
+	if field.Kind() == reflect.Bool {
+		value, err = applyBoolMode(value, opts)
+		if err != nil {
+			return fmt.Errorf("%w: field %s", err, name)
+		}
+	}
+
+	if err := setFieldValue(field, value, opts); err != nil {
+		return err
+	}
+
+	if err := validateBounds(field, opts); err != nil {
+		return fmt.Errorf("%w: field %s", err, name)
+	}
+
+	return nil
+}
+
+// trimValue removes the padding around a field's raw value.
+// By default whitespace is trimmed from both sides. The trimSet option
+// replaces whitespace with a custom set of characters, and the trim option
+// restricts trimming to the "left" or "right" side ("both" is the default).
+// Zeros are significant on the right of numeric values, so they are only
+// trimmed from their left, e.g. "00100" with trimSet=0 is "100".
+func trimValue(value string, opts tagOptions, numeric bool) (string, error) {
+	set, hasSet := opts["trimSet"]
+
+	rightSet := set
+	if numeric {
+		rightSet = strings.ReplaceAll(set, "0", "")
+	}
+
+	trimLeft := func(s string) string {
+		if hasSet {
+			return strings.TrimLeft(s, set)
+		}
+		return strings.TrimLeftFunc(s, unicode.IsSpace)
+	}
+
+	trimRight := func(s string) string {
+		if hasSet {
+			return strings.TrimRight(s, rightSet)
+		}
+		return strings.TrimRightFunc(s, unicode.IsSpace)
+	}
+
+	switch direction := opts["trim"]; direction {
+	case "", "both":
+		return trimRight(trimLeft(value)), nil
+	case "left":
+		return trimLeft(value), nil
+	case "right":
+		return trimRight(value), nil
+	default:
+		return "", fmt.Errorf("%w: trim=%s", ErrTagInvalidOption, direction)
+	}
+}
+
+// defaultCurrencySymbols are stripped by the currency option when no
+// symbols are given.
+var defaultCurrencySymbols = []string{"$", "€", "£", "¥"}
+
+// stripCurrency removes a leading currency symbol or code from value,
+// along with any spaces following it. symbols is a "|" separated list,
+// e.g. "USD|EUR|$"; when empty, defaultCurrencySymbols is used.
+func stripCurrency(value, symbols string) string {
+	candidates := defaultCurrencySymbols
+	if symbols != "" {
+		candidates = strings.Split(symbols, "|")
+	}
+
+	for _, symbol := range candidates {
+		if rest, ok := strings.CutPrefix(value, symbol); ok {
+			return strings.TrimLeft(rest, " ")
+		}
+	}
+
+	return value
+}
+
+// isNumericKind reports whether values of kind k are parsed as numbers.
+func isNumericKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return false
+	}
+}
+
+// isAllZeros reports whether value is made only of zeros and,
+// optionally, a decimal point, e.g. "00000000" or "0000.00".
+func isAllZeros(value string) bool {
+	return strings.Contains(value, "0") && strings.Trim(value, "0.") == ""
+}
+
+// checkRuneBoundaries returns an error if the segment data[start:end]
+// splits a multibyte UTF-8 sequence at either of its boundaries.
+func checkRuneBoundaries(data []byte, start, end int) error {
+	if start < len(data) && !utf8.RuneStart(data[start]) {
+		return fmt.Errorf("%w: start offset %d splits a multibyte sequence", ErrInvalidUTF8, start)
+	}
+
+	if end < len(data) && !utf8.RuneStart(data[end]) {
+		return fmt.Errorf("%w: end offset %d splits a multibyte sequence", ErrInvalidUTF8, end)
+	}
+
 	return nil
 }
+
+// applyControls handles control characters in a string field's value
+// according to the controls option: "keep" (default) leaves them untouched,
+// "strip" removes them and "reject" returns an error if any is found.
+func applyControls(value string, opts tagOptions) (string, error) {
+	switch mode := opts["controls"]; mode {
+	case "", "keep":
+		return value, nil
+	case "strip":
+		return strings.Map(func(r rune) rune {
+			if unicode.IsControl(r) {
+				return -1
+			}
+			return r
+		}, value), nil
+	case "reject":
+		if i := strings.IndexFunc(value, unicode.IsControl); i >= 0 {
+			r, _ := utf8.DecodeRuneInString(value[i:])
+			return "", fmt.Errorf("%w: %q at index %d", ErrControlCharacter, r, i)
+		}
+		return value, nil
+	default:
+		return "", fmt.Errorf("%w: controls=%s", ErrTagInvalidOption, mode)
+	}
+}
+
+// applyInvalidUTF8 handles invalid UTF-8 sequences in a string field's value
+// according to the invalidUTF8 option: "keep" (default) leaves them untouched,
+// "replace" substitutes each run of invalid bytes with the invalidUTF8Char
+// option (U+FFFD by default) and "reject" returns an error.
+func applyInvalidUTF8(value string, opts tagOptions) (string, error) {
+	switch mode := opts["invalidUTF8"]; mode {
+	case "", "keep":
+		return value, nil
+	case "replace":
+		replacement, ok := opts["invalidUTF8Char"]
+		if !ok {
+			replacement = string(utf8.RuneError)
+		}
+		return strings.ToValidUTF8(value, replacement), nil
+	case "reject":
+		if !utf8.ValidString(value) {
+			return "", fmt.Errorf("%w: %q", ErrInvalidUTF8, value)
+		}
+		return value, nil
+	default:
+		return "", fmt.Errorf("%w: invalidUTF8=%s", ErrTagInvalidOption, mode)
+	}
+}
+
+// applyStorePad pads a string field's trimmed value back to the minimum
+// width given by the storePad option. The value is padded on the right by
+// default, or on the left with storePadDir=left, using storePadChar (a space
+// by default). Values that are already wide enough are left untouched.
+func applyStorePad(value string, opts tagOptions) (string, error) {
+	option, ok := opts["storePad"]
+	if !ok {
+		return value, nil
+	}
+
+	width, err := strconv.Atoi(option)
+	if err != nil || width < 0 {
+		return "", fmt.Errorf("%w: storePad=%s", ErrTagInvalidOption, option)
+	}
+
+	char, ok := opts["storePadChar"]
+	if !ok {
+		char = " "
+	}
+	if utf8.RuneCountInString(char) != 1 {
+		return "", fmt.Errorf("%w: storePadChar=%s", ErrTagInvalidOption, char)
+	}
+
+	n := width - utf8.RuneCountInString(value)
+	if n <= 0 {
+		return value, nil
+	}
+
+	padding := strings.Repeat(char, n)
+
+	switch direction := opts["storePadDir"]; direction {
+	case "", "right":
+		return value + padding, nil
+	case "left":
+		return padding + value, nil
+	default:
+		return "", fmt.Errorf("%w: storePadDir=%s", ErrTagInvalidOption, direction)
+	}
+}
+
+// applyBoolMode converts a boolean field's value according to the bool option.
+// With "numeric" the value is parsed as an integer where zero (or a blank
+// value) is false and anything else is true, e.g. "001" is true.
+func applyBoolMode(value string, opts tagOptions) (string, error) {
+	switch mode := opts["bool"]; mode {
+	case "":
+		return value, nil
+	case "numeric":
+		if value == "" {
+			return strconv.FormatBool(false), nil
+		}
+
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return "", errors.Join(ErrInvalidBooleanValue, err)
+		}
+		return strconv.FormatBool(n != 0), nil
+	default:
+		return "", fmt.Errorf("%w: bool=%s", ErrTagInvalidOption, mode)
+	}
+}
@@ -1,7 +1,9 @@
 package fixedlength
 
 import (
+	"errors"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -196,3 +198,948 @@ func TestTypeAliasesWithCustomUnmarshaler(t *testing.T) {
 		t.Errorf("Expected v.B to be 12, got %d", v.B)
 	}
 }
+
+func TestTrimValue(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		opts    tagOptions
+		numeric bool
+		want    string
+		wantErr error
+	}{
+		{
+			name:  "default trims whitespace on both sides",
+			value: "  abc  ",
+			opts:  tagOptions{},
+			want:  "abc",
+		},
+		{
+			name:  "trim left only",
+			value: "  abc  ",
+			opts:  tagOptions{"trim": "left"},
+			want:  "abc  ",
+		},
+		{
+			name:  "trim right only",
+			value: "  abc  ",
+			opts:  tagOptions{"trim": "right"},
+			want:  "  abc",
+		},
+		{
+			name:  "trim set on both sides",
+			value: "* *abc* *",
+			opts:  tagOptions{"trimSet": " *"},
+			want:  "abc",
+		},
+		{
+			name:  "trim set on the left",
+			value: "* *abc* *",
+			opts:  tagOptions{"trimSet": " *", "trim": "left"},
+			want:  "abc* *",
+		},
+		{
+			name:  "trim set on the right",
+			value: "* *abc* *",
+			opts:  tagOptions{"trimSet": " *", "trim": "right"},
+			want:  "* *abc",
+		},
+		{
+			name:  "trim set does not trim other whitespace",
+			value: "\t*abc*\t",
+			opts:  tagOptions{"trimSet": "*"},
+			want:  "\t*abc*\t",
+		},
+		{
+			name:    "numbers keep trailing zeros",
+			value:   " 00100 ",
+			opts:    tagOptions{"trimSet": " 0"},
+			numeric: true,
+			want:    "100",
+		},
+		{
+			name:    "invalid trim direction",
+			value:   "abc",
+			opts:    tagOptions{"trim": "middle"},
+			wantErr: ErrTagInvalidOption,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := trimValue(tt.value, tt.opts, tt.numeric)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+
+			if got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestUnmarshalTrimSet(t *testing.T) {
+	type testStruct struct {
+		Name   string `range:"0,8,trimSet= *"`
+		Amount int    `range:"8,14,trimSet=* ,trim=left"`
+	}
+
+	data := []byte("**ABC * *  *42")
+	var v testStruct
+	err := Unmarshal(data, &v)
+	if err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+
+	if v.Name != "ABC" {
+		t.Errorf("Expected v.Name to be 'ABC', got '%s'", v.Name)
+	}
+
+	if v.Amount != 42 {
+		t.Errorf("Expected v.Amount to be 42, got %d", v.Amount)
+	}
+}
+
+func TestUnmarshalBestEffort(t *testing.T) {
+	type nested struct {
+		A string `range:"0,1"`
+		B int    `range:"1,2"`
+	}
+
+	type testStruct struct {
+		Nested nested
+		C      int     `range:"2,4"`
+		D      float64 `range:"4,-1"`
+		E      bool    `range:"0,1"`
+	}
+
+	data := []byte("AB1234.5")
+	v := testStruct{E: true}
+	errs, err := UnmarshalBestEffort(data, &v)
+	if err != nil {
+		t.Fatalf("UnmarshalBestEffort failed: %v", err)
+	}
+
+	if len(errs) != 2 {
+		t.Fatalf("Expected 2 field errors, got %d: %v", len(errs), errs)
+	}
+
+	if !errors.Is(errs["Nested.B"], ErrInvalidIntValue) {
+		t.Errorf("Expected Nested.B error to be ErrInvalidIntValue, got %v", errs["Nested.B"])
+	}
+
+	if !errors.Is(errs["E"], ErrInvalidBooleanValue) {
+		t.Errorf("Expected E error to be ErrInvalidBooleanValue, got %v", errs["E"])
+	}
+
+	if v.Nested.A != "A" {
+		t.Errorf("Expected v.Nested.A to be 'A', got '%s'", v.Nested.A)
+	}
+
+	if v.Nested.B != 0 {
+		t.Errorf("Expected v.Nested.B to be 0, got %d", v.Nested.B)
+	}
+
+	if v.C != 12 {
+		t.Errorf("Expected v.C to be 12, got %d", v.C)
+	}
+
+	if v.D != 34.5 {
+		t.Errorf("Expected v.D to be 34.5, got %f", v.D)
+	}
+
+	if v.E {
+		t.Errorf("Expected v.E to be reset to false")
+	}
+}
+
+func TestUnmarshalBestEffortError(t *testing.T) {
+	var v int
+	errs, err := UnmarshalBestEffort([]byte("AB2D"), v)
+	if err == nil {
+		t.Fatalf("Expected UnmarshalBestEffort to fail")
+	}
+
+	if errs != nil {
+		t.Errorf("Expected no field errors, got %v", errs)
+	}
+}
+
+func TestApplyControls(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		opts    tagOptions
+		want    string
+		wantErr error
+	}{
+		{
+			name:  "keep by default",
+			value: "A\x00B\tC",
+			opts:  tagOptions{},
+			want:  "A\x00B\tC",
+		},
+		{
+			name:  "keep",
+			value: "A\x00B\tC",
+			opts:  tagOptions{"controls": "keep"},
+			want:  "A\x00B\tC",
+		},
+		{
+			name:  "strip NUL and tab",
+			value: "A\x00B\tC",
+			opts:  tagOptions{"controls": "strip"},
+			want:  "ABC",
+		},
+		{
+			name:    "reject NUL",
+			value:   "A\x00B",
+			opts:    tagOptions{"controls": "reject"},
+			wantErr: ErrControlCharacter,
+		},
+		{
+			name:    "reject tab",
+			value:   "A\tB",
+			opts:    tagOptions{"controls": "reject"},
+			wantErr: ErrControlCharacter,
+		},
+		{
+			name:  "reject accepts clean values",
+			value: "ABC",
+			opts:  tagOptions{"controls": "reject"},
+			want:  "ABC",
+		},
+		{
+			name:    "invalid mode",
+			value:   "ABC",
+			opts:    tagOptions{"controls": "drop"},
+			wantErr: ErrTagInvalidOption,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := applyControls(tt.value, tt.opts)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+
+			if got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestUnmarshalControls(t *testing.T) {
+	type testStruct struct {
+		Stripped string `range:"0,4,controls=strip"`
+		Kept     string `range:"4,8"`
+	}
+
+	data := []byte("A\x00\tBC\x00DE")
+	var v testStruct
+	if err := Unmarshal(data, &v); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+
+	if v.Stripped != "AB" {
+		t.Errorf("Expected v.Stripped to be 'AB', got %q", v.Stripped)
+	}
+
+	if v.Kept != "C\x00DE" {
+		t.Errorf("Expected v.Kept to be 'C\\x00DE', got %q", v.Kept)
+	}
+
+	type rejectStruct struct {
+		Name string `range:"0,4,controls=reject"`
+	}
+
+	var r rejectStruct
+	err := Unmarshal(data, &r)
+	if !errors.Is(err, ErrControlCharacter) {
+		t.Fatalf("Expected ErrControlCharacter, got %v", err)
+	}
+
+	if want := "field Name"; !strings.Contains(err.Error(), want) {
+		t.Errorf("Expected error to contain %q, got %q", want, err.Error())
+	}
+
+	// Multibyte control characters are reported whole
+	_, err = applyControls("AB\u0085C", tagOptions{"controls": "reject"})
+	if want := `'\u0085' at index 2`; err == nil || !strings.Contains(err.Error(), want) {
+		t.Errorf("Expected error to contain %q, got %v", want, err)
+	}
+}
+
+func TestUnmarshalWithBase(t *testing.T) {
+	type testStruct struct {
+		A string `range:"0,3"`
+		B int    `range:"3,-1"`
+	}
+
+	data := []byte("HDR01ABC12")
+	var v testStruct
+	if err := UnmarshalWithBase(data, &v, 5); err != nil {
+		t.Fatalf("UnmarshalWithBase failed: %v", err)
+	}
+
+	if v.A != "ABC" {
+		t.Errorf("Expected v.A to be 'ABC', got '%s'", v.A)
+	}
+
+	if v.B != 12 {
+		t.Errorf("Expected v.B to be 12, got %d", v.B)
+	}
+
+	t.Run("negative base", func(t *testing.T) {
+		err := UnmarshalWithBase(data, &v, -1)
+		if !errors.Is(err, ErrInvalidBaseOffset) {
+			t.Errorf("Expected ErrInvalidBaseOffset, got %v", err)
+		}
+	})
+
+	t.Run("base beyond data", func(t *testing.T) {
+		err := UnmarshalWithBase(data, &v, len(data)+1)
+		if !errors.Is(err, ErrInvalidBaseOffset) {
+			t.Errorf("Expected ErrInvalidBaseOffset, got %v", err)
+		}
+	})
+}
+
+func TestApplyInvalidUTF8(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		opts    tagOptions
+		want    string
+		wantErr error
+	}{
+		{
+			name:  "keep by default",
+			value: "A\xffB",
+			opts:  tagOptions{},
+			want:  "A\xffB",
+		},
+		{
+			name:  "replace with replacement character",
+			value: "A\xff\xfeB\xc3",
+			opts:  tagOptions{"invalidUTF8": "replace"},
+			want:  "A�B�",
+		},
+		{
+			name:  "replace with configured character",
+			value: "A\xffB",
+			opts:  tagOptions{"invalidUTF8": "replace", "invalidUTF8Char": "?"},
+			want:  "A?B",
+		},
+		{
+			name:  "replace keeps valid multibyte characters",
+			value: "Añ\xffB",
+			opts:  tagOptions{"invalidUTF8": "replace"},
+			want:  "Añ�B",
+		},
+		{
+			name:    "reject",
+			value:   "A\xffB",
+			opts:    tagOptions{"invalidUTF8": "reject"},
+			wantErr: ErrInvalidUTF8,
+		},
+		{
+			name:  "reject accepts valid values",
+			value: "Añ",
+			opts:  tagOptions{"invalidUTF8": "reject"},
+			want:  "Añ",
+		},
+		{
+			name:    "invalid mode",
+			value:   "A",
+			opts:    tagOptions{"invalidUTF8": "drop"},
+			wantErr: ErrTagInvalidOption,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := applyInvalidUTF8(tt.value, tt.opts)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+
+			if got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestUnmarshalInvalidUTF8(t *testing.T) {
+	type testStruct struct {
+		Replaced string `range:"0,4,invalidUTF8=replace"`
+		Rejected string `range:"4,8,invalidUTF8=reject"`
+	}
+
+	var v testStruct
+	err := Unmarshal([]byte("A\xffBCD\xfeEF"), &v)
+	if !errors.Is(err, ErrInvalidUTF8) {
+		t.Fatalf("Expected ErrInvalidUTF8, got %v", err)
+	}
+
+	if v.Replaced != "A�BC" {
+		t.Errorf("Expected v.Replaced to be %q, got %q", "A�BC", v.Replaced)
+	}
+}
+
+func TestApplyBoolMode(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		opts    tagOptions
+		want    string
+		wantErr error
+	}{
+		{
+			name:  "default mode leaves value untouched",
+			value: "001",
+			opts:  tagOptions{},
+			want:  "001",
+		},
+		{
+			name:  "numeric zero",
+			value: "000",
+			opts:  tagOptions{"bool": "numeric"},
+			want:  "false",
+		},
+		{
+			name:  "numeric leading zero true",
+			value: "001",
+			opts:  tagOptions{"bool": "numeric"},
+			want:  "true",
+		},
+		{
+			name:  "numeric non-one true",
+			value: "042",
+			opts:  tagOptions{"bool": "numeric"},
+			want:  "true",
+		},
+		{
+			name:  "numeric blank",
+			value: "",
+			opts:  tagOptions{"bool": "numeric"},
+			want:  "false",
+		},
+		{
+			name:    "numeric invalid",
+			value:   "0X1",
+			opts:    tagOptions{"bool": "numeric"},
+			wantErr: ErrInvalidBooleanValue,
+		},
+		{
+			name:    "invalid mode",
+			value:   "1",
+			opts:    tagOptions{"bool": "yesno"},
+			wantErr: ErrTagInvalidOption,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := applyBoolMode(tt.value, tt.opts)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+
+			if got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestUnmarshalNumericBool(t *testing.T) {
+	type testStruct struct {
+		Active  bool `range:"0,3,bool=numeric"`
+		Deleted bool `range:"3,6,bool=numeric"`
+		Blank   bool `range:"6,9,bool=numeric"`
+	}
+
+	v := testStruct{Deleted: true, Blank: true}
+	if err := Unmarshal([]byte("001000   "), &v); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+
+	if !v.Active {
+		t.Errorf("Expected v.Active to be true")
+	}
+
+	if v.Deleted {
+		t.Errorf("Expected v.Deleted to be false")
+	}
+
+	if v.Blank {
+		t.Errorf("Expected v.Blank to be false")
+	}
+}
+
+func TestIsAllZeros(t *testing.T) {
+	tests := []struct {
+		value string
+		want  bool
+	}{
+		{"00000000", true},
+		{"0", true},
+		{"0000.00", true},
+		{"", false},
+		{".", false},
+		{"00000001", false},
+		{"10000000", false},
+		{"0000.01", false},
+	}
+
+	for _, tt := range tests {
+		if got := isAllZeros(tt.value); got != tt.want {
+			t.Errorf("isAllZeros(%q): expected %v, got %v", tt.value, tt.want, got)
+		}
+	}
+}
+
+// zeroDate fails to unmarshal the all-zero date, like a
+// time.Parse based date would.
+type zeroDate struct {
+	Value string
+}
+
+var _ Unmarshaler = (*zeroDate)(nil)
+
+func (d *zeroDate) Unmarshal(data []byte) error {
+	if string(data) == "00000000" {
+		return errors.New("month out of range")
+	}
+
+	d.Value = string(data)
+	return nil
+}
+
+func TestUnmarshalZeroAsNull(t *testing.T) {
+	type testStruct struct {
+		Date   zeroDate `range:"0,8,zeroAsNull"`
+		Amount float64  `range:"8,15,zeroAsNull,min=1"`
+		Count  int      `range:"15,18"`
+	}
+
+	v := testStruct{Date: zeroDate{"stale"}, Amount: 1}
+	if err := Unmarshal([]byte("000000000000.00000"), &v); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+
+	if v.Date.Value != "" {
+		t.Errorf("Expected v.Date to be zero, got %q", v.Date.Value)
+	}
+
+	if v.Amount != 0 {
+		t.Errorf("Expected v.Amount to be 0, got %f", v.Amount)
+	}
+
+	if v.Count != 0 {
+		t.Errorf("Expected v.Count to be 0, got %d", v.Count)
+	}
+
+	if err := Unmarshal([]byte("199703220015.50001"), &v); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+
+	if v.Date.Value != "19970322" {
+		t.Errorf("Expected v.Date to be '19970322', got %q", v.Date.Value)
+	}
+
+	if v.Amount != 15.5 {
+		t.Errorf("Expected v.Amount to be 15.5, got %f", v.Amount)
+	}
+}
+
+func TestCheckRuneBoundaries(t *testing.T) {
+	// "ñ" is encoded as the two bytes 0xC3 0xB1
+	data := []byte("AñB")
+
+	tests := []struct {
+		name    string
+		start   int
+		end     int
+		wantErr bool
+	}{
+		{name: "whole data", start: 0, end: 4},
+		{name: "around multibyte character", start: 1, end: 3},
+		{name: "start splits character", start: 2, end: 4, wantErr: true},
+		{name: "end splits character", start: 0, end: 2, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkRuneBoundaries(data, tt.start, tt.end)
+			if tt.wantErr && !errors.Is(err, ErrInvalidUTF8) {
+				t.Errorf("expected ErrInvalidUTF8, got %v", err)
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestUnmarshalTruncatedMultibyte(t *testing.T) {
+	type testStruct struct {
+		Name string `range:"0,2,invalidUTF8=reject"`
+	}
+
+	var v testStruct
+	err := Unmarshal([]byte("AñB"), &v)
+	if !errors.Is(err, ErrInvalidUTF8) {
+		t.Fatalf("Expected ErrInvalidUTF8, got %v", err)
+	}
+
+	for _, want := range []string{"field Name", "end offset 2"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected error to contain %q, got %q", want, err.Error())
+		}
+	}
+}
+
+func TestStripCurrency(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		symbols string
+		want    string
+	}{
+		{name: "default dollar", value: "$  1550.85", want: "1550.85"},
+		{name: "default euro", value: "€1550.85", want: "1550.85"},
+		{name: "default pound", value: "£ 12", want: "12"},
+		{name: "no symbol", value: "1550.85", want: "1550.85"},
+		{name: "code not in defaults", value: "USD1550.85", want: "USD1550.85"},
+		{name: "configured code", value: "USD1550.85", symbols: "USD|EUR", want: "1550.85"},
+		{name: "configured code with space", value: "EUR 7.50", symbols: "USD|EUR", want: "7.50"},
+		{name: "configured set replaces defaults", value: "$5", symbols: "USD", want: "$5"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := stripCurrency(tt.value, tt.symbols); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestUnmarshalCurrency(t *testing.T) {
+	type testStruct struct {
+		Income  float64 `range:"0,10,currency"`
+		Expense float64 `range:"10,-1,currency=USD|$"`
+	}
+
+	var v testStruct
+	if err := Unmarshal([]byte("$  1550.85USD 200.10"), &v); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+
+	if v.Income != 1550.85 {
+		t.Errorf("Expected v.Income to be 1550.85, got %f", v.Income)
+	}
+
+	if v.Expense != 200.1 {
+		t.Errorf("Expected v.Expense to be 200.1, got %f", v.Expense)
+	}
+}
+
+func TestApplyStorePad(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		opts    tagOptions
+		want    string
+		wantErr error
+	}{
+		{
+			name:  "no store pad",
+			value: "42",
+			opts:  tagOptions{},
+			want:  "42",
+		},
+		{
+			name:  "pad right with spaces by default",
+			value: "42",
+			opts:  tagOptions{"storePad": "5"},
+			want:  "42   ",
+		},
+		{
+			name:  "pad left with zeros",
+			value: "42",
+			opts:  tagOptions{"storePad": "5", "storePadChar": "0", "storePadDir": "left"},
+			want:  "00042",
+		},
+		{
+			name:  "counts characters rather than bytes",
+			value: "ñ",
+			opts:  tagOptions{"storePad": "3", "storePadChar": "*"},
+			want:  "ñ**",
+		},
+		{
+			name:  "wider values are untouched",
+			value: "123456",
+			opts:  tagOptions{"storePad": "5"},
+			want:  "123456",
+		},
+		{
+			name:    "invalid width",
+			value:   "42",
+			opts:    tagOptions{"storePad": "five"},
+			wantErr: ErrTagInvalidOption,
+		},
+		{
+			name:    "invalid pad char",
+			value:   "42",
+			opts:    tagOptions{"storePad": "5", "storePadChar": "ab"},
+			wantErr: ErrTagInvalidOption,
+		},
+		{
+			name:    "invalid direction",
+			value:   "42",
+			opts:    tagOptions{"storePad": "5", "storePadDir": "up"},
+			wantErr: ErrTagInvalidOption,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := applyStorePad(tt.value, tt.opts)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+
+			if got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestUnmarshalStorePad(t *testing.T) {
+	type testStruct struct {
+		Code    string `range:"0,6,storePad=4,storePadChar=0,storePadDir=left"`
+		Name    string `range:"6,-1,storePad=8"`
+		Numeric int    `range:"0,6"`
+	}
+
+	var v testStruct
+	if err := Unmarshal([]byte("    42  Ann   "), &v); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+
+	if v.Code != "0042" {
+		t.Errorf("Expected v.Code to be '0042', got %q", v.Code)
+	}
+
+	if v.Name != "Ann     " {
+		t.Errorf("Expected v.Name to be 'Ann     ', got %q", v.Name)
+	}
+
+	if v.Numeric != 42 {
+		t.Errorf("Expected v.Numeric to be 42, got %d", v.Numeric)
+	}
+}
+
+func TestUnmarshalMaxDepth(t *testing.T) {
+	type level3 struct {
+		A string `range:"0,1"`
+	}
+	type level2 struct{ L3 level3 }
+	type level1 struct{ L2 level2 }
+	type testStruct struct{ L1 level1 }
+
+	// MaxDepth is global, so this test must not run in parallel
+	defer func(depth int) { MaxDepth = depth }(MaxDepth)
+
+	MaxDepth = 3
+	var v testStruct
+	if err := Unmarshal([]byte("A"), &v); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+
+	if v.L1.L2.L3.A != "A" {
+		t.Errorf("Expected v.L1.L2.L3.A to be 'A', got '%s'", v.L1.L2.L3.A)
+	}
+
+	MaxDepth = 2
+	err := Unmarshal([]byte("A"), &v)
+	if !errors.Is(err, ErrMaxDepthExceeded) {
+		t.Fatalf("Expected ErrMaxDepthExceeded, got %v", err)
+	}
+
+	if want := "L1.L2.L3"; !strings.Contains(err.Error(), want) {
+		t.Errorf("Expected error to contain %q, got %q", want, err.Error())
+	}
+
+	_, err = UnmarshalBestEffort([]byte("A"), &v)
+	if !errors.Is(err, ErrMaxDepthExceeded) {
+		t.Errorf("Expected UnmarshalBestEffort to fail with ErrMaxDepthExceeded, got %v", err)
+	}
+
+	_, err = Fields(v)
+	if !errors.Is(err, ErrMaxDepthExceeded) {
+		t.Errorf("Expected Fields to fail with ErrMaxDepthExceeded, got %v", err)
+	}
+}
+
+func TestUnmarshalZeroPadding(t *testing.T) {
+	type testStruct struct {
+		AllZeros    int     `range:"0,5,trimSet=0"`
+		LeadingZero int     `range:"5,10,trimSet=0"`
+		Amount      float64 `range:"10,15,trimSet=0 ,trim=left"`
+		Unsigned    uint    `range:"15,20,trimSet= 0"`
+		Code        string  `range:"0,5,trimSet=0"`
+	}
+
+	v := testStruct{AllZeros: 1, Amount: 1, Unsigned: 1}
+	if err := Unmarshal([]byte("0000000042  000000  "), &v); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+
+	if v.AllZeros != 0 {
+		t.Errorf("Expected v.AllZeros to be 0, got %d", v.AllZeros)
+	}
+
+	if v.LeadingZero != 42 {
+		t.Errorf("Expected v.LeadingZero to be 42, got %d", v.LeadingZero)
+	}
+
+	if v.Amount != 0 {
+		t.Errorf("Expected v.Amount to be 0, got %f", v.Amount)
+	}
+
+	if v.Unsigned != 0 {
+		t.Errorf("Expected v.Unsigned to be 0, got %d", v.Unsigned)
+	}
+
+	// Strings made only of padding are still empty
+	if v.Code != "" {
+		t.Errorf("Expected v.Code to be empty, got %q", v.Code)
+	}
+
+	// Trailing zeros are part of the number
+	type trailingStruct struct {
+		Hundred     int     `range:"0,5,trimSet=0"`
+		TenThousand uint    `range:"5,10,trimSet=0 "`
+		Amount      float64 `range:"10,16,trimSet=0"`
+	}
+
+	var tz trailingStruct
+	if err := Unmarshal([]byte("0010010000001.50"), &tz); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+
+	if tz.Hundred != 100 {
+		t.Errorf("Expected tz.Hundred to be 100, got %d", tz.Hundred)
+	}
+
+	if tz.TenThousand != 10000 {
+		t.Errorf("Expected tz.TenThousand to be 10000, got %d", tz.TenThousand)
+	}
+
+	if tz.Amount != 1.5 {
+		t.Errorf("Expected tz.Amount to be 1.5, got %f", tz.Amount)
+	}
+
+	// Blank numeric fields without zeros are still invalid
+	type blankStruct struct {
+		Count int `range:"0,5,trimSet= 0"`
+	}
+
+	var b blankStruct
+	if err := Unmarshal([]byte("     "), &b); !errors.Is(err, ErrInvalidIntValue) {
+		t.Errorf("Expected ErrInvalidIntValue, got %v", err)
+	}
+}
+
+func TestUnmarshalWithDefaults(t *testing.T) {
+	type address struct {
+		City    string `range:"10,16"`
+		Country string `range:"16,18"`
+	}
+
+	type testStruct struct {
+		Name    string `range:"0,6"`
+		Count   int    `range:"6,10"`
+		Address address
+		Rate    float64 `range:"18,22"`
+		Active  bool    `range:"22,27"`
+	}
+
+	defaults := testStruct{
+		Name:    "nobody",
+		Count:   1,
+		Address: address{City: "Paris", Country: "FR"},
+		Rate:    0.5,
+		Active:  true,
+	}
+
+	// Count and Address.City are blank, Rate and Active are beyond the end
+	data := []byte("Ann             US")
+	var v testStruct
+	if err := UnmarshalWithDefaults(data, &v, &defaults); err != nil {
+		t.Fatalf("UnmarshalWithDefaults failed: %v", err)
+	}
+
+	want := testStruct{
+		Name:    "Ann",
+		Count:   1,
+		Address: address{City: "Paris", Country: "US"},
+		Rate:    0.5,
+		Active:  true,
+	}
+	if v != want {
+		t.Errorf("Expected %+v, got %+v", want, v)
+	}
+
+	// Present fields keep their parsed values, even when zero
+	data = []byte("Bob   0000Lyon  ES0.00false")
+	if err := UnmarshalWithDefaults(data, &v, defaults); err != nil {
+		t.Fatalf("UnmarshalWithDefaults failed: %v", err)
+	}
+
+	want = testStruct{
+		Name:    "Bob",
+		Address: address{City: "Lyon", Country: "ES"},
+	}
+	if v != want {
+		t.Errorf("Expected %+v, got %+v", want, v)
+	}
+}
+
+func TestUnmarshalWithDefaultsError(t *testing.T) {
+	type testStruct struct {
+		Count int `range:"0,4"`
+	}
+
+	var v testStruct
+
+	t.Run("mismatched defaults", func(t *testing.T) {
+		err := UnmarshalWithDefaults([]byte("0001"), &v, struct{ Count int }{})
+		if !errors.Is(err, ErrInvalidDefaults) {
+			t.Errorf("Expected ErrInvalidDefaults, got %v", err)
+		}
+	})
+
+	t.Run("nil defaults", func(t *testing.T) {
+		err := UnmarshalWithDefaults([]byte("0001"), &v, nil)
+		if !errors.Is(err, ErrInvalidDefaults) {
+			t.Errorf("Expected ErrInvalidDefaults, got %v", err)
+		}
+	})
+
+	t.Run("invalid value", func(t *testing.T) {
+		err := UnmarshalWithDefaults([]byte("00X1"), &v, testStruct{Count: 1})
+		if !errors.Is(err, ErrInvalidIntValue) {
+			t.Errorf("Expected ErrInvalidIntValue, got %v", err)
+		}
+	})
+}
@@ -10,22 +10,43 @@ import (
 var (
 	ErrInvalidBooleanValue = errors.New("fixedlength: invalid boolean value")
 	ErrInvalidIntValue     = errors.New("fixedlength: invalid int value")
+	ErrInvalidUintValue    = errors.New("fixedlength: invalid uint value")
 	ErrInvalidFloatValue   = errors.New("fixedlength: invalid float value")
 	ErrUnsupportedKind     = errors.New("fixedlength: unsupported kind")
+	ErrControlCharacter    = errors.New("fixedlength: control character in string value")
+	ErrInvalidUTF8         = errors.New("fixedlength: invalid UTF-8 in string value")
 )
 
 // setFieldValue sets the value for a struct field using reflection.
-func setFieldValue(field reflect.Value, value string) error {
+// Types registered with RegisterEnum are looked up by name, and kinds
+// registered with RegisterKind are parsed by their handler unless the
+// field implements Unmarshaler.
+func setFieldValue(field reflect.Value, value string, opts tagOptions) error {
+	if e, ok := lookupEnum(field.Type()); ok {
+		return e.set(field, value)
+	}
+
+	if handler, ok := lookupKind(field.Kind()); ok && !implementsUnmarshaler(field) {
+		return setKindValue(field, handler, value, opts)
+	}
+
 	switch field.Kind() {
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		intVal, err := strconv.ParseInt(value, 10, 64)
+		intVal, err := strconv.ParseInt(value, 10, field.Type().Bits())
 		if err != nil {
 			return errors.Join(ErrInvalidIntValue, err)
 		}
 		field.SetInt(intVal)
 
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		uintVal, err := strconv.ParseUint(value, 10, field.Type().Bits())
+		if err != nil {
+			return errors.Join(ErrInvalidUintValue, err)
+		}
+		field.SetUint(uintVal)
+
 	case reflect.Float32, reflect.Float64:
-		floatVal, err := strconv.ParseFloat(value, 64)
+		floatVal, err := strconv.ParseFloat(value, field.Type().Bits())
 		if err != nil {
 			return errors.Join(ErrInvalidFloatValue, err)
 		}
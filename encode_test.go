@@ -30,6 +30,22 @@ func TestSetFieldValue(t *testing.T) {
 			wantErr:   ErrInvalidIntValue,
 		},
 
+		// Uint tests
+		{
+			name:      "valid uint",
+			kind:      reflect.Uint,
+			value:     "42",
+			wantValue: uint64(42),
+			wantErr:   nil,
+		},
+		{
+			name:      "invalid uint",
+			kind:      reflect.Uint,
+			value:     "-42",
+			wantValue: uint64(0),
+			wantErr:   ErrInvalidUintValue,
+		},
+
 		// Float tests
 		{
 			name:      "valid float",
@@ -95,6 +111,8 @@ func TestSetFieldValue(t *testing.T) {
 			switch tt.kind {
 			case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
 				field = reflect.New(reflect.TypeOf(int64(0))).Elem()
+			case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+				field = reflect.New(reflect.TypeOf(uint64(0))).Elem()
 			case reflect.Float32, reflect.Float64:
 				field = reflect.New(reflect.TypeOf(float64(0))).Elem()
 			case reflect.String:
@@ -106,7 +124,7 @@ func TestSetFieldValue(t *testing.T) {
 			}
 
 			// Call setFieldValue and check for errors
-			err := setFieldValue(field, tt.value)
+			err := setFieldValue(field, tt.value, nil)
 
 			// Check for expected error
 			if err != nil && tt.wantErr == nil {
@@ -126,6 +144,10 @@ func TestSetFieldValue(t *testing.T) {
 					if field.Int() != tt.wantValue {
 						t.Errorf("expected int value %v, got %v", tt.wantValue, field.Int())
 					}
+				case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+					if field.Uint() != tt.wantValue {
+						t.Errorf("expected uint value %v, got %v", tt.wantValue, field.Uint())
+					}
 				case reflect.Float32, reflect.Float64:
 					if field.Float() != tt.wantValue {
 						t.Errorf("expected float value %v, got %v", tt.wantValue, field.Float())
@@ -0,0 +1,118 @@
+package fixedlength
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+var (
+	ErrUnknownEnumName  = errors.New("fixedlength: unknown enum name")
+	ErrUnknownEnumValue = errors.New("fixedlength: unknown enum value")
+)
+
+// enum holds the names registered for an integer type, e.g. "RED" for Red.
+type enum struct {
+	values map[string]reflect.Value
+	names  map[uint64]string
+}
+
+var (
+	enumsMu sync.RWMutex
+	enums   = map[reflect.Type]enum{}
+)
+
+// RegisterEnum registers the names of the integer type T, typically the
+// inverse of its stringer-generated String method, e.g.
+//
+//	fixedlength.RegisterEnum(map[string]Color{"RED": Red, "GREEN": Green})
+//
+// Fields of type T are then decoded by looking up their trimmed content in
+// names, and unknown names fail with ErrUnknownEnumName. Marshal writes the
+// name of the field's value, the first in alphabetical order if it has
+// several, and fails with ErrUnknownEnumValue if it has none.
+//
+// Registering T again replaces its names. RegisterEnum panics if names is empty.
+func RegisterEnum[T ~int | ~int8 | ~int16 | ~int32 | ~int64 | ~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64](names map[string]T) {
+	if len(names) == 0 {
+		panic("fixedlength: RegisterEnum names is empty")
+	}
+
+	e := enum{
+		values: make(map[string]reflect.Value, len(names)),
+		names:  make(map[uint64]string, len(names)),
+	}
+
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	for _, name := range sorted {
+		value := reflect.ValueOf(names[name])
+		e.values[name] = value
+
+		if _, ok := e.names[enumKey(value)]; !ok {
+			e.names[enumKey(value)] = name
+		}
+	}
+
+	enumsMu.Lock()
+	defer enumsMu.Unlock()
+
+	enums[reflect.TypeFor[T]()] = e
+}
+
+// lookupEnum returns the names registered for typ, if any.
+func lookupEnum(typ reflect.Type) (enum, bool) {
+	enumsMu.RLock()
+	defer enumsMu.RUnlock()
+
+	e, ok := enums[typ]
+	return e, ok
+}
+
+// enumKey returns the bits of an integer value, regardless of its signedness.
+func enumKey(v reflect.Value) uint64 {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return uint64(v.Int())
+	default:
+		return v.Uint()
+	}
+}
+
+// formatEnumValue returns the decimal representation of an integer value.
+func formatEnumValue(v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	default:
+		return strconv.FormatUint(v.Uint(), 10)
+	}
+}
+
+// set sets field to the value registered for name.
+func (e enum) set(field reflect.Value, name string) error {
+	value, ok := e.values[name]
+	if !ok {
+		return fmt.Errorf("%w: %q for %v", ErrUnknownEnumName, name, field.Type())
+	}
+
+	field.Set(value)
+	return nil
+}
+
+// name returns the name registered for the value of field.
+func (e enum) name(field reflect.Value) (string, error) {
+	name, ok := e.names[enumKey(field)]
+	if !ok {
+		return "", fmt.Errorf("%w: %v(%s)", ErrUnknownEnumValue, field.Type(), formatEnumValue(field))
+	}
+
+	return name, nil
+}
@@ -0,0 +1,98 @@
+package fixedlength
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+type enumColor int
+
+const (
+	enumRed enumColor = iota + 1
+	enumGreen
+	enumBlue
+)
+
+type enumSize uint8
+
+func init() {
+	RegisterEnum(map[string]enumColor{"RED": enumRed, "GREEN": enumGreen, "BLUE": enumBlue, "AZURE": enumBlue})
+	RegisterEnum(map[string]enumSize{"S": 1, "M": 2, "L": 3})
+}
+
+func TestUnmarshalEnum(t *testing.T) {
+	type testStruct struct {
+		Color enumColor `range:"0,6"`
+		Size  enumSize  `range:"6,7"`
+		Count int       `range:"7,-1"`
+	}
+
+	var v testStruct
+	if err := Unmarshal([]byte("GREEN L42"), &v); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+
+	if v.Color != enumGreen {
+		t.Errorf("Expected v.Color to be %d, got %d", enumGreen, v.Color)
+	}
+
+	if v.Size != 3 {
+		t.Errorf("Expected v.Size to be 3, got %d", v.Size)
+	}
+
+	// Types that are not registered are still parsed by kind
+	if v.Count != 42 {
+		t.Errorf("Expected v.Count to be 42, got %d", v.Count)
+	}
+
+	err := Unmarshal([]byte("PINK  L42"), &v)
+	if !errors.Is(err, ErrUnknownEnumName) {
+		t.Errorf("Expected ErrUnknownEnumName, got %v", err)
+	}
+}
+
+func TestMarshalEnum(t *testing.T) {
+	type testStruct struct {
+		Color enumColor `range:"0,6"`
+		Size  enumSize  `range:"6,7"`
+	}
+
+	got, err := Marshal(testStruct{Color: enumRed, Size: 2})
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	if want := "RED   M"; string(got) != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+
+	// Values with several names use the first in alphabetical order
+	got, err = Marshal(testStruct{Color: enumBlue, Size: 1})
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	if want := "AZURE S"; string(got) != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+
+	_, err = Marshal(testStruct{Color: 9, Size: 1})
+	if !errors.Is(err, ErrUnknownEnumValue) {
+		t.Fatalf("Expected ErrUnknownEnumValue, got %v", err)
+	}
+
+	if want := "enumColor(9)"; !strings.Contains(err.Error(), want) {
+		t.Errorf("Expected error to contain %q, got %q", want, err.Error())
+	}
+}
+
+func TestRegisterEnumPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Expected RegisterEnum to panic with no names")
+		}
+	}()
+
+	RegisterEnum(map[string]enumSize{})
+}
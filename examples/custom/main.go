@@ -22,7 +22,10 @@ type PersonBirthDate struct {
 	time.Time
 }
 
-var _ fixedlength.Unmarshaler = (*PersonBirthDate)(nil)
+var (
+	_ fixedlength.Unmarshaler = (*PersonBirthDate)(nil)
+	_ fixedlength.Marshaler   = (*PersonBirthDate)(nil)
+)
 
 func (p *PersonBirthDate) Unmarshal(data []byte) error {
 	// Parse the birth date
@@ -38,11 +41,16 @@ func (p *PersonBirthDate) Unmarshal(data []byte) error {
 	return nil
 }
 
+func (p *PersonBirthDate) MarshalFixed() ([]byte, error) {
+	// Format the birth date back to its original layout
+	return []byte(p.Format("20060102")), nil
+}
+
 type Person struct {
 	FullName  string          `range:"0,20"`
 	BirthDate PersonBirthDate `range:"20,28"`
 	SSN       string          `range:"28,37"`
-	Income    float64         `range:"37,-1"`
+	Income    float64         `range:"37,-1,prec=2"`
 }
 
 func main() {
@@ -59,5 +67,11 @@ func main() {
 			log.Fatalf("Unmarshal failed: %v", err)
 		}
 		fmt.Printf("%+v\n", p)
+
+		data, err := fixedlength.Marshal(p)
+		if err != nil {
+			log.Fatalf("Marshal failed: %v", err)
+		}
+		fmt.Printf("%s\n", data)
 	}
 }
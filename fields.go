@@ -0,0 +1,130 @@
+package fixedlength
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// FieldInfo describes a single field of a fixed-length record
+// without the need of a tagged struct.
+type FieldInfo struct {
+	// Name is the key under which the decoded value is returned.
+	Name string
+	// Start and End are the bounds of the field, with the same
+	// semantics as the values of a `range:"<start>,<end>"` tag.
+	Start int
+	End   int
+	// Kind is the Go kind the field is decoded into.
+	// The zero value (reflect.Invalid) decodes the field as a string.
+	Kind reflect.Kind
+	// SemType is a free-form semantic type hint, such as "email" or "phone",
+	// taken verbatim from the semtype tag option. It does not affect parsing.
+	SemType string
+}
+
+// Fields returns the layout described by the range tags of v, which must be
+// a struct or a pointer to a struct. Nested structs are flattened in
+// declaration order and their fields are named by their dotted path,
+// e.g. "Nested.A". Fields without a range tag are omitted.
+func Fields(v any) ([]FieldInfo, error) {
+	typ := reflect.TypeOf(v)
+	if typ != nil && typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if typ == nil || typ.Kind() != reflect.Struct {
+		return nil, InvalidUnmarshalError{reflect.TypeOf(v)}
+	}
+
+	return appendFields(nil, typ, "", 0)
+}
+
+// appendFields appends the layout of the struct type typ, which is nested
+// depth levels below the type passed to Fields, to fields.
+func appendFields(fields []FieldInfo, typ reflect.Type, path string, depth int) ([]FieldInfo, error) {
+	if depth > MaxDepth {
+		return nil, fmt.Errorf("%w: %s", ErrMaxDepthExceeded, path)
+	}
+
+	for i := 0; i < typ.NumField(); i++ {
+		structField := typ.Field(i)
+
+		name := structField.Name
+		if path != "" {
+			name = path + "." + name
+		}
+
+		// Recursively flatten the struct, as Unmarshal does
+		if structField.Type.Kind() == reflect.Struct && !implementsUnmarshaler(reflect.New(structField.Type).Elem()) {
+			var err error
+			fields, err = appendFields(fields, structField.Type, name, depth+1)
+			if err != nil {
+				return nil, err
+			}
+
+			continue
+		}
+
+		tag := structField.Tag.Get("range")
+		if tag == "" || tag == lineNoTag {
+			continue
+		}
+
+		start, end, err := parseRange(tag)
+		if err != nil {
+			return nil, fmt.Errorf("%w: field %s", err, name)
+		}
+
+		fields = append(fields, FieldInfo{
+			Name:    name,
+			Start:   start,
+			End:     end,
+			Kind:    structField.Type.Kind(),
+			SemType: parseTagOptions(tag)["semtype"],
+		})
+	}
+
+	return fields, nil
+}
+
+// kindTypes maps the kinds supported by DecodeFields to their Go types.
+var kindTypes = map[reflect.Kind]reflect.Type{
+	reflect.Invalid: reflect.TypeOf(""),
+	reflect.String:  reflect.TypeOf(""),
+	reflect.Bool:    reflect.TypeOf(false),
+	reflect.Int:     reflect.TypeOf(int(0)),
+	reflect.Int8:    reflect.TypeOf(int8(0)),
+	reflect.Int16:   reflect.TypeOf(int16(0)),
+	reflect.Int32:   reflect.TypeOf(int32(0)),
+	reflect.Int64:   reflect.TypeOf(int64(0)),
+	reflect.Uint:    reflect.TypeOf(uint(0)),
+	reflect.Uint8:   reflect.TypeOf(uint8(0)),
+	reflect.Uint16:  reflect.TypeOf(uint16(0)),
+	reflect.Uint32:  reflect.TypeOf(uint32(0)),
+	reflect.Uint64:  reflect.TypeOf(uint64(0)),
+	reflect.Float32: reflect.TypeOf(float32(0)),
+	reflect.Float64: reflect.TypeOf(float64(0)),
+}
+
+// DecodeFields parses data according to layout and returns the decoded
+// values keyed by field name. Each value has the Go type of its FieldInfo.Kind,
+// so numbers are returned as numbers rather than strings.
+func DecodeFields(data []byte, layout []FieldInfo) (map[string]any, error) {
+	values := make(map[string]any, len(layout))
+
+	for _, info := range layout {
+		typ, ok := kindTypes[info.Kind]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s: field %s", ErrUnsupportedKind, info.Kind, info.Name)
+		}
+
+		field := reflect.New(typ).Elem()
+		tag := fmt.Sprintf("%d,%d", info.Start, info.End)
+		if err := unmarshalField(data, field, info.Name, tag); err != nil {
+			return nil, fmt.Errorf("%w: field %s", err, info.Name)
+		}
+
+		values[info.Name] = field.Interface()
+	}
+
+	return values, nil
+}
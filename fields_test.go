@@ -0,0 +1,120 @@
+package fixedlength
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestDecodeFields(t *testing.T) {
+	layout := []FieldInfo{
+		{Name: "FullName", Start: 0, End: 20},
+		{Name: "BirthDate", Start: 20, End: 28, Kind: reflect.String},
+		{Name: "SSN", Start: 28, End: 37, Kind: reflect.Int64},
+		{Name: "Income", Start: 37, End: -1, Kind: reflect.Float64},
+	}
+
+	data := []byte("Olivia Parker       199703221112223331550.85   ")
+	got, err := DecodeFields(data, layout)
+	if err != nil {
+		t.Fatalf("DecodeFields failed: %v", err)
+	}
+
+	want := map[string]any{
+		"FullName":  "Olivia Parker",
+		"BirthDate": "19970322",
+		"SSN":       int64(111222333),
+		"Income":    1550.85,
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestDecodeFieldsError(t *testing.T) {
+	tests := []struct {
+		name    string
+		layout  []FieldInfo
+		wantErr error
+	}{
+		{
+			name:    "invalid value",
+			layout:  []FieldInfo{{Name: "A", Start: 0, End: 2, Kind: reflect.Int}},
+			wantErr: ErrInvalidIntValue,
+		},
+		{
+			name:    "unsupported kind",
+			layout:  []FieldInfo{{Name: "A", Start: 0, End: 2, Kind: reflect.Slice}},
+			wantErr: ErrUnsupportedKind,
+		},
+		{
+			name:    "ineffectual range",
+			layout:  []FieldInfo{{Name: "A", Start: 2, End: 2}},
+			wantErr: ErrTagInefectualRange,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := DecodeFields([]byte("AB12"), tt.layout)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestFields(t *testing.T) {
+	type contact struct {
+		Email string `range:"20,40,semtype=email"`
+		Phone string `range:"40,50,semtype=x-intl-phone"`
+	}
+
+	type testStruct struct {
+		Name    string `range:"0,20"`
+		Contact contact
+		Date    CustomTime `range:"50,58"`
+		Income  float64    `range:"58,-1,min=0"`
+		Ignored string
+	}
+
+	want := []FieldInfo{
+		{Name: "Name", Start: 0, End: 20, Kind: reflect.String},
+		{Name: "Contact.Email", Start: 20, End: 40, Kind: reflect.String, SemType: "email"},
+		{Name: "Contact.Phone", Start: 40, End: 50, Kind: reflect.String, SemType: "x-intl-phone"},
+		{Name: "Date", Start: 50, End: 58, Kind: reflect.Struct},
+		{Name: "Income", Start: 58, End: -1, Kind: reflect.Float64},
+	}
+
+	for _, v := range []any{testStruct{}, &testStruct{}} {
+		got, err := Fields(v)
+		if err != nil {
+			t.Fatalf("Fields failed: %v", err)
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("expected %+v, got %+v", want, got)
+		}
+	}
+}
+
+func TestFieldsError(t *testing.T) {
+	t.Run("invalid range", func(t *testing.T) {
+		type testStruct struct {
+			A string `range:"a,1"`
+		}
+
+		_, err := Fields(testStruct{})
+		if !errors.Is(err, ErrTagInvalidRangeValues) {
+			t.Errorf("expected ErrTagInvalidRangeValues, got %v", err)
+		}
+	})
+
+	t.Run("non-struct", func(t *testing.T) {
+		_, err := Fields(42)
+		if !errors.As(err, &InvalidUnmarshalError{}) {
+			t.Errorf("expected InvalidUnmarshalError, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,540 @@
+package fixedlength
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var ErrInvalidFormat = errors.New("fixedlength: value does not match format")
+
+// FieldOpts holds the options of a field's range tag, keyed by option name.
+// For example `range:"0,10,format=signedcents,decimals=2"` has the options
+// "format" and "decimals".
+type FieldOpts map[string]string
+
+// FormatCodec is the interface implemented by the codecs selected with the
+// format tag option.
+//
+// Decode receives the field's bytes once they have been trimmed, and returns
+// the text that is then parsed according to the field's kind (or handed to
+// its Unmarshaler). Encode receives the text produced for the field by its
+// kind (or its Marshaler) and returns the bytes that are then padded into
+// the field's range.
+type FormatCodec interface {
+	Decode(data []byte, opts FieldOpts) (string, error)
+	Encode(value string, opts FieldOpts) ([]byte, error)
+}
+
+// decimalsCodec is implemented by codecs that store numbers with implied
+// decimals, so Marshal can round float fields to that many decimals first.
+type decimalsCodec interface {
+	decimals(opts FieldOpts) (int, error)
+}
+
+// binaryCodec is implemented by codecs of binary formats, whose bytes can
+// look like padding. Their fields are not trimmed before being decoded, and
+// their encoded values are padded as padding says rather than by kind.
+type binaryCodec interface {
+	padding() (pad string, right bool)
+}
+
+var (
+	formatsMu sync.RWMutex
+	formats   = map[string]FormatCodec{
+		"urlencoded":  urlEncodedCodec{},
+		"fraction":    fractionCodec{},
+		"signedcents": signedCentsCodec{},
+		"overpunch":   overpunchCodec{},
+		"zoned":       zonedCodec{},
+		"comp3":       comp3Codec{},
+	}
+)
+
+// RegisterFormat makes codec available to fields tagged with format=name.
+// Registering a name that is already in use, including the name of a
+// built-in format, replaces its codec. RegisterFormat panics if codec is nil.
+func RegisterFormat(name string, codec FormatCodec) {
+	if codec == nil {
+		panic("fixedlength: RegisterFormat codec is nil")
+	}
+
+	formatsMu.Lock()
+	defer formatsMu.Unlock()
+
+	formats[name] = codec
+}
+
+// isBinaryFormat reports whether the format option selects a binary codec.
+func isBinaryFormat(opts tagOptions) bool {
+	codec, ok, _ := lookupFormat(opts)
+	_, binary := codec.(binaryCodec)
+	return ok && binary
+}
+
+// lookupFormat returns the codec selected by the format option, if any.
+func lookupFormat(opts tagOptions) (FormatCodec, bool, error) {
+	name, ok := opts["format"]
+	if !ok || name == "" {
+		return nil, false, nil
+	}
+
+	formatsMu.RLock()
+	defer formatsMu.RUnlock()
+
+	codec, ok := formats[name]
+	if !ok {
+		return nil, false, fmt.Errorf("%w: format=%s", ErrTagInvalidOption, name)
+	}
+
+	return codec, true, nil
+}
+
+// decodeFormat decodes a trimmed field value according to the format option.
+// Values without a format are returned unchanged.
+func decodeFormat(value string, opts tagOptions) (string, error) {
+	codec, ok, err := lookupFormat(opts)
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return value, nil
+	}
+
+	return codec.Decode([]byte(value), FieldOpts(opts))
+}
+
+// encodeFormat encodes a formatted field value according to the format option.
+// Values without a format are returned unchanged.
+func encodeFormat(value string, opts tagOptions) (string, error) {
+	codec, ok, err := lookupFormat(opts)
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return value, nil
+	}
+
+	data, err := codec.Encode(value, FieldOpts(opts))
+	return string(data), err
+}
+
+// urlEncodedCodec handles percent-encoded text.
+type urlEncodedCodec struct{}
+
+func (urlEncodedCodec) Decode(data []byte, _ FieldOpts) (string, error) {
+	decoded, err := url.QueryUnescape(string(data))
+	if err != nil {
+		return "", errors.Join(ErrInvalidFormat, err)
+	}
+	return decoded, nil
+}
+
+func (urlEncodedCodec) Encode(value string, _ FieldOpts) ([]byte, error) {
+	return []byte(url.QueryEscape(value)), nil
+}
+
+// fractionCodec handles "numerator/denominator" values, such as "3/8".
+type fractionCodec struct{}
+
+// Decode returns the quotient of the fraction.
+// A whole number without a denominator is returned as is.
+func (fractionCodec) Decode(data []byte, _ FieldOpts) (string, error) {
+	value := string(data)
+	numerator, denominator, found := strings.Cut(value, "/")
+
+	n, err := strconv.ParseFloat(strings.TrimSpace(numerator), 64)
+	if err != nil {
+		return "", errors.Join(ErrInvalidFormat, err)
+	}
+
+	if !found {
+		return strconv.FormatFloat(n, 'g', -1, 64), nil
+	}
+
+	d, err := strconv.ParseFloat(strings.TrimSpace(denominator), 64)
+	if err != nil {
+		return "", errors.Join(ErrInvalidFormat, err)
+	}
+
+	if d == 0 {
+		return "", fmt.Errorf("%w: zero denominator in %q", ErrInvalidFormat, value)
+	}
+
+	return strconv.FormatFloat(n/d, 'g', -1, 64), nil
+}
+
+// Encode returns the decimal value as a fraction in lowest terms,
+// e.g. "0.375" is "3/8". Whole numbers are returned without a denominator.
+// Values that need a denominator larger than the denominator option
+// (10000 by default) are approximated by the closest fraction within it,
+// so the float 0.3333333333333333 is "1/3".
+func (fractionCodec) Encode(value string, opts FieldOpts) ([]byte, error) {
+	r, ok := new(big.Rat).SetString(value)
+	if !ok {
+		return nil, fmt.Errorf("%w: fraction %q", ErrInvalidFormat, value)
+	}
+
+	maxDenominator := int64(10000)
+	if option, ok := opts["denominator"]; ok {
+		d, err := strconv.ParseInt(option, 10, 64)
+		if err != nil || d < 1 {
+			return nil, fmt.Errorf("%w: denominator=%s", ErrTagInvalidOption, option)
+		}
+		maxDenominator = d
+	}
+
+	r = limitDenominator(r, big.NewInt(maxDenominator))
+	if r.IsInt() {
+		return []byte(r.Num().String()), nil
+	}
+	return []byte(r.String()), nil
+}
+
+// limitDenominator returns the fraction closest to r whose denominator is
+// at most limit, found by walking the continued fraction expansion of r.
+func limitDenominator(r *big.Rat, limit *big.Int) *big.Rat {
+	if r.Denom().Cmp(limit) <= 0 {
+		return r
+	}
+
+	// Convergents p0/q0 and p1/q1 of |r|, and the remainder n/d left to expand
+	p0, q0, p1, q1 := big.NewInt(0), big.NewInt(1), big.NewInt(1), big.NewInt(0)
+	n, d := new(big.Int).Abs(r.Num()), new(big.Int).Set(r.Denom())
+
+	for {
+		a := new(big.Int).Quo(n, d)
+		q2 := new(big.Int).Add(q0, new(big.Int).Mul(a, q1))
+		if q2.Cmp(limit) > 0 {
+			break
+		}
+
+		p0, q0, p1, q1 = p1, q1, new(big.Int).Add(p0, new(big.Int).Mul(a, p1)), q2
+		n, d = d, new(big.Int).Sub(n, new(big.Int).Mul(a, d))
+	}
+
+	// The best approximation is either the last convergent or the
+	// semiconvergent with the largest denominator within limit
+	k := new(big.Int).Quo(new(big.Int).Sub(limit, q0), q1)
+	semi := new(big.Rat).SetFrac(
+		new(big.Int).Add(p0, new(big.Int).Mul(k, p1)),
+		new(big.Int).Add(q0, new(big.Int).Mul(k, q1)),
+	)
+	best := new(big.Rat).SetFrac(p1, q1)
+
+	abs := new(big.Rat).Abs(r)
+	if new(big.Rat).Abs(new(big.Rat).Sub(semi, abs)).Cmp(new(big.Rat).Abs(new(big.Rat).Sub(best, abs))) < 0 {
+		best = semi
+	}
+
+	if r.Sign() < 0 {
+		best.Neg(best)
+	}
+	return best
+}
+
+// signedCentsCodec handles amounts stored as digits with implied decimals
+// and a leading or trailing sign, such as "0000155085+" for 1550.85.
+// The number of implied decimals is given by the decimals option and
+// defaults to 2. Values are encoded with a trailing sign unless the sign
+// option is "leading".
+type signedCentsCodec struct{}
+
+func (signedCentsCodec) decimals(opts FieldOpts) (int, error) {
+	return parseDecimals(opts, 2)
+}
+
+func (signedCentsCodec) Decode(data []byte, opts FieldOpts) (string, error) {
+	decimals, err := parseDecimals(opts, 2)
+	if err != nil {
+		return "", err
+	}
+
+	value := string(data)
+	sign, digits := "+", value
+	switch {
+	case strings.HasPrefix(digits, "+"), strings.HasPrefix(digits, "-"):
+		sign, digits = digits[:1], digits[1:]
+	case strings.HasSuffix(digits, "+"), strings.HasSuffix(digits, "-"):
+		sign, digits = digits[len(digits)-1:], digits[:len(digits)-1]
+	}
+
+	if !isDigits(digits) {
+		return "", fmt.Errorf("%w: signedcents %q", ErrInvalidFormat, value)
+	}
+
+	return sign + insertDecimalPoint(digits, decimals), nil
+}
+
+func (signedCentsCodec) Encode(value string, opts FieldOpts) ([]byte, error) {
+	decimals, err := parseDecimals(opts, 2)
+	if err != nil {
+		return nil, err
+	}
+
+	negative, digits, err := removeDecimalPoint(value, decimals)
+	if err != nil {
+		return nil, err
+	}
+
+	sign := "+"
+	if negative {
+		sign = "-"
+	}
+
+	switch position := opts["sign"]; position {
+	case "", "trailing":
+		return []byte(digits + sign), nil
+	case "leading":
+		return []byte(sign + digits), nil
+	default:
+		return nil, fmt.Errorf("%w: sign=%s", ErrTagInvalidOption, position)
+	}
+}
+
+// overpunchCodec handles signed overpunch numbers, where the sign is
+// carried by the last character: "{" and "A" to "I" are the positive
+// digits 0 to 9, "}" and "J" to "R" the negative ones, e.g. "1550J" is -15501.
+// Plain digits are positive. The decimals option sets the number of
+// implied decimals and defaults to 0.
+type overpunchCodec struct{}
+
+const (
+	overpunchPositive = "{ABCDEFGHI"
+	overpunchNegative = "}JKLMNOPQR"
+)
+
+func (overpunchCodec) decimals(opts FieldOpts) (int, error) {
+	return parseDecimals(opts, 0)
+}
+
+func (overpunchCodec) Decode(data []byte, opts FieldOpts) (string, error) {
+	return decodeSignedDigit(string(data), opts, "overpunch", overpunchPositive, overpunchNegative)
+}
+
+func (overpunchCodec) Encode(value string, opts FieldOpts) ([]byte, error) {
+	return encodeSignedDigit(value, opts, overpunchPositive, overpunchNegative)
+}
+
+// zonedCodec handles ASCII zoned decimal numbers, where negative numbers
+// carry the sign in the zone of their last digit: "p" to "y" are the
+// negative digits 0 to 9, e.g. "1550q" is -15501. The decimals option sets
+// the number of implied decimals and defaults to 0.
+type zonedCodec struct{}
+
+const (
+	zonedPositive = "0123456789"
+	zonedNegative = "pqrstuvwxy"
+)
+
+func (zonedCodec) decimals(opts FieldOpts) (int, error) {
+	return parseDecimals(opts, 0)
+}
+
+func (zonedCodec) Decode(data []byte, opts FieldOpts) (string, error) {
+	return decodeSignedDigit(string(data), opts, "zoned", zonedPositive, zonedNegative)
+}
+
+func (zonedCodec) Encode(value string, opts FieldOpts) ([]byte, error) {
+	return encodeSignedDigit(value, opts, zonedPositive, zonedNegative)
+}
+
+// comp3Codec handles packed decimal (COBOL COMP-3) numbers, which store two
+// digits per byte followed by a sign nibble: 0xD or 0xB for negative numbers
+// and 0xC, 0xF, 0xA or 0xE for positive ones, e.g. 0x12 0x34 0x5D is -12345.
+// The decimals option sets the number of implied decimals and defaults to 0.
+//
+// Packed bytes can look like padding (0x20 is a space), so the field is
+// decoded untrimmed. Encoded values are padded on the left with zero bytes,
+// which are leading zero digits.
+type comp3Codec struct{}
+
+func (comp3Codec) decimals(opts FieldOpts) (int, error) {
+	return parseDecimals(opts, 0)
+}
+
+func (comp3Codec) padding() (string, bool) {
+	return "\x00", true
+}
+
+func (comp3Codec) Decode(data []byte, opts FieldOpts) (string, error) {
+	decimals, err := parseDecimals(opts, 0)
+	if err != nil {
+		return "", err
+	}
+
+	if len(data) == 0 {
+		return "", fmt.Errorf("%w: comp3 %q", ErrInvalidFormat, data)
+	}
+
+	digits := make([]byte, 0, len(data)*2-1)
+	for i, b := range data {
+		high, low := b>>4, b&0x0f
+		if high > 9 || (i < len(data)-1 && low > 9) {
+			return "", fmt.Errorf("%w: comp3 %q", ErrInvalidFormat, data)
+		}
+
+		digits = append(digits, '0'+high)
+		if i < len(data)-1 {
+			digits = append(digits, '0'+low)
+		}
+	}
+
+	var sign string
+	switch data[len(data)-1] & 0x0f {
+	case 0x0c, 0x0f, 0x0a, 0x0e:
+		sign = "+"
+	case 0x0d, 0x0b:
+		sign = "-"
+	default:
+		return "", fmt.Errorf("%w: comp3 %q has no sign nibble", ErrInvalidFormat, data)
+	}
+
+	return sign + insertDecimalPoint(string(digits), decimals), nil
+}
+
+func (comp3Codec) Encode(value string, opts FieldOpts) ([]byte, error) {
+	decimals, err := parseDecimals(opts, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	negative, digits, err := removeDecimalPoint(value, decimals)
+	if err != nil {
+		return nil, err
+	}
+
+	// The digits and the sign nibble must fill whole bytes
+	if len(digits)%2 == 0 {
+		digits = "0" + digits
+	}
+
+	sign := byte(0x0c)
+	if negative {
+		sign = 0x0d
+	}
+
+	data := make([]byte, 0, (len(digits)+1)/2)
+	for i := 0; i+1 < len(digits); i += 2 {
+		data = append(data, (digits[i]-'0')<<4|(digits[i+1]-'0'))
+	}
+
+	return append(data, (digits[len(digits)-1]-'0')<<4|sign), nil
+}
+
+// decodeSignedDigit decodes a number whose last character is looked up in
+// positive and negative, which hold the characters for the digits 0 to 9.
+func decodeSignedDigit(value string, opts FieldOpts, format, positive, negative string) (string, error) {
+	decimals, err := parseDecimals(opts, 0)
+	if err != nil {
+		return "", err
+	}
+
+	if value == "" {
+		return "", fmt.Errorf("%w: %s %q", ErrInvalidFormat, format, value)
+	}
+
+	digits, last := value[:len(value)-1], value[len(value)-1:]
+
+	sign := "+"
+	if i := strings.Index(negative, last); i >= 0 {
+		sign, last = "-", strconv.Itoa(i)
+	} else if i := strings.Index(positive, last); i >= 0 {
+		last = strconv.Itoa(i)
+	}
+
+	digits += last
+	if !isDigits(digits) {
+		return "", fmt.Errorf("%w: %s %q", ErrInvalidFormat, format, value)
+	}
+
+	return sign + insertDecimalPoint(digits, decimals), nil
+}
+
+// encodeSignedDigit encodes a number replacing its last digit with the
+// matching character from positive or negative.
+func encodeSignedDigit(value string, opts FieldOpts, positive, negative string) ([]byte, error) {
+	decimals, err := parseDecimals(opts, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	isNegative, digits, err := removeDecimalPoint(value, decimals)
+	if err != nil {
+		return nil, err
+	}
+
+	table := positive
+	if isNegative {
+		table = negative
+	}
+
+	last := digits[len(digits)-1] - '0'
+	return []byte(digits[:len(digits)-1] + table[last:last+1]), nil
+}
+
+// parseDecimals returns the number of implied decimals set by the decimals
+// option, or fallback if it is not set.
+func parseDecimals(opts FieldOpts, fallback int) (int, error) {
+	option, ok := opts["decimals"]
+	if !ok {
+		return fallback, nil
+	}
+
+	decimals, err := strconv.Atoi(option)
+	if err != nil || decimals < 0 {
+		return 0, fmt.Errorf("%w: decimals=%s", ErrTagInvalidOption, option)
+	}
+
+	return decimals, nil
+}
+
+// isDigits reports whether s is a non-empty string of ASCII digits.
+func isDigits(s string) bool {
+	return s != "" && strings.Trim(s, "0123456789") == ""
+}
+
+// insertDecimalPoint places a decimal point before the last decimals digits,
+// e.g. "155085" with 2 decimals is "1550.85".
+func insertDecimalPoint(digits string, decimals int) string {
+	if decimals == 0 {
+		return digits
+	}
+
+	// Make sure there is at least one digit before the decimal point
+	if len(digits) <= decimals {
+		digits = strings.Repeat("0", decimals-len(digits)+1) + digits
+	}
+
+	point := len(digits) - decimals
+	return digits[:point] + "." + digits[point:]
+}
+
+// removeDecimalPoint is the inverse of insertDecimalPoint. It splits a decimal
+// value such as "-1550.85" into its sign and its digits with exactly decimals
+// implied decimals, "155085" for 2 decimals. Values with more decimals than
+// that fail rather than being rounded.
+func removeDecimalPoint(value string, decimals int) (bool, string, error) {
+	negative := strings.HasPrefix(value, "-")
+	unsigned := strings.TrimLeft(value, "+-")
+
+	whole, fraction, _ := strings.Cut(unsigned, ".")
+	if whole == "" {
+		whole = "0"
+	}
+
+	if !isDigits(whole) || (fraction != "" && !isDigits(fraction)) {
+		return false, "", fmt.Errorf("%w: %q is not a decimal number", ErrInvalidFormat, value)
+	}
+
+	fraction = strings.TrimRight(fraction, "0")
+	if len(fraction) > decimals {
+		return false, "", fmt.Errorf("%w: %q has more than %d decimals", ErrInvalidFormat, value, decimals)
+	}
+
+	return negative, whole + fraction + strings.Repeat("0", decimals-len(fraction)), nil
+}
@@ -0,0 +1,647 @@
+package fixedlength
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestDecodeFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		opts    tagOptions
+		want    string
+		wantErr error
+	}{
+		{
+			name:  "no format",
+			value: "a%20b",
+			opts:  tagOptions{},
+			want:  "a%20b",
+		},
+		{
+			name:  "urlencoded",
+			value: "Caf%C3%A9+%26+Bar%2C+Inc.",
+			opts:  tagOptions{"format": "urlencoded"},
+			want:  "Café & Bar, Inc.",
+		},
+		{
+			name:    "malformed urlencoded",
+			value:   "100%",
+			opts:    tagOptions{"format": "urlencoded"},
+			wantErr: ErrInvalidFormat,
+		},
+		{
+			name:  "fraction",
+			value: "3/8",
+			opts:  tagOptions{"format": "fraction"},
+			want:  "0.375",
+		},
+		{
+			name:  "fraction with spaces",
+			value: "1 / 4",
+			opts:  tagOptions{"format": "fraction"},
+			want:  "0.25",
+		},
+		{
+			name:  "fraction whole number",
+			value: "5",
+			opts:  tagOptions{"format": "fraction"},
+			want:  "5",
+		},
+		{
+			name:    "fraction zero denominator",
+			value:   "3/0",
+			opts:    tagOptions{"format": "fraction"},
+			wantErr: ErrInvalidFormat,
+		},
+		{
+			name:    "fraction invalid numerator",
+			value:   "x/8",
+			opts:    tagOptions{"format": "fraction"},
+			wantErr: ErrInvalidFormat,
+		},
+		{
+			name:    "fraction invalid denominator",
+			value:   "3/",
+			opts:    tagOptions{"format": "fraction"},
+			wantErr: ErrInvalidFormat,
+		},
+		{
+			name:  "signedcents trailing positive",
+			value: "0000155085+",
+			opts:  tagOptions{"format": "signedcents"},
+			want:  "+00001550.85",
+		},
+		{
+			name:  "signedcents trailing negative",
+			value: "0000155085-",
+			opts:  tagOptions{"format": "signedcents", "decimals": "2"},
+			want:  "-00001550.85",
+		},
+		{
+			name:  "signedcents leading negative",
+			value: "-0000155085",
+			opts:  tagOptions{"format": "signedcents"},
+			want:  "-00001550.85",
+		},
+		{
+			name:  "signedcents unsigned",
+			value: "155085",
+			opts:  tagOptions{"format": "signedcents"},
+			want:  "+1550.85",
+		},
+		{
+			name:  "signedcents fewer digits than decimals",
+			value: "5-",
+			opts:  tagOptions{"format": "signedcents", "decimals": "3"},
+			want:  "-0.005",
+		},
+		{
+			name:  "signedcents no decimals",
+			value: "0042-",
+			opts:  tagOptions{"format": "signedcents", "decimals": "0"},
+			want:  "-0042",
+		},
+		{
+			name:    "signedcents sign only",
+			value:   "+",
+			opts:    tagOptions{"format": "signedcents"},
+			wantErr: ErrInvalidFormat,
+		},
+		{
+			name:    "signedcents invalid digits",
+			value:   "00015.085+",
+			opts:    tagOptions{"format": "signedcents"},
+			wantErr: ErrInvalidFormat,
+		},
+		{
+			name:    "signedcents invalid decimals",
+			value:   "155085+",
+			opts:    tagOptions{"format": "signedcents", "decimals": "-1"},
+			wantErr: ErrTagInvalidOption,
+		},
+		{
+			name:  "overpunch positive",
+			value: "0015508E",
+			opts:  tagOptions{"format": "overpunch", "decimals": "2"},
+			want:  "+001550.85",
+		},
+		{
+			name:  "overpunch negative",
+			value: "1550J",
+			opts:  tagOptions{"format": "overpunch"},
+			want:  "-15501",
+		},
+		{
+			name:  "overpunch negative zero",
+			value: "155}",
+			opts:  tagOptions{"format": "overpunch", "decimals": "1"},
+			want:  "-155.0",
+		},
+		{
+			name:  "overpunch plain digits",
+			value: "1550",
+			opts:  tagOptions{"format": "overpunch"},
+			want:  "+1550",
+		},
+		{
+			name:    "overpunch invalid",
+			value:   "15X0J",
+			opts:    tagOptions{"format": "overpunch"},
+			wantErr: ErrInvalidFormat,
+		},
+		{
+			name:  "zoned positive",
+			value: "155085",
+			opts:  tagOptions{"format": "zoned", "decimals": "2"},
+			want:  "+1550.85",
+		},
+		{
+			name:  "zoned negative",
+			value: "15508u",
+			opts:  tagOptions{"format": "zoned", "decimals": "2"},
+			want:  "-1550.85",
+		},
+		{
+			name:    "zoned empty",
+			value:   "",
+			opts:    tagOptions{"format": "zoned"},
+			wantErr: ErrInvalidFormat,
+		},
+		{
+			name:  "comp3 positive",
+			value: "\x01\x55\x08\x5c",
+			opts:  tagOptions{"format": "comp3", "decimals": "2"},
+			want:  "+01550.85",
+		},
+		{
+			name:  "comp3 negative",
+			value: "\x12\x34\x5d",
+			opts:  tagOptions{"format": "comp3"},
+			want:  "-12345",
+		},
+		{
+			name:  "comp3 unsigned",
+			value: "\x42\x0f",
+			opts:  tagOptions{"format": "comp3"},
+			want:  "+420",
+		},
+		{
+			name:    "comp3 invalid digit",
+			value:   "\x1a\x2c",
+			opts:    tagOptions{"format": "comp3"},
+			wantErr: ErrInvalidFormat,
+		},
+		{
+			name:    "comp3 missing sign",
+			value:   "\x12\x34",
+			opts:    tagOptions{"format": "comp3"},
+			wantErr: ErrInvalidFormat,
+		},
+		{
+			name:    "comp3 empty",
+			value:   "",
+			opts:    tagOptions{"format": "comp3"},
+			wantErr: ErrInvalidFormat,
+		},
+		{
+			name:    "unknown format",
+			value:   "abc",
+			opts:    tagOptions{"format": "base32"},
+			wantErr: ErrTagInvalidOption,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeFormat(tt.value, tt.opts)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+
+			if got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestEncodeFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		opts    tagOptions
+		want    string
+		wantErr error
+	}{
+		{
+			name:  "no format",
+			value: "a b",
+			opts:  tagOptions{},
+			want:  "a b",
+		},
+		{
+			name:  "urlencoded",
+			value: "Café & Bar, Inc.",
+			opts:  tagOptions{"format": "urlencoded"},
+			want:  "Caf%C3%A9+%26+Bar%2C+Inc.",
+		},
+		{
+			name:  "fraction",
+			value: "0.375",
+			opts:  tagOptions{"format": "fraction"},
+			want:  "3/8",
+		},
+		{
+			name:  "fraction whole number",
+			value: "5",
+			opts:  tagOptions{"format": "fraction"},
+			want:  "5",
+		},
+		{
+			name:  "fraction approximates repeating decimals",
+			value: "0.3333333333333333",
+			opts:  tagOptions{"format": "fraction"},
+			want:  "1/3",
+		},
+		{
+			name:  "fraction negative",
+			value: "-2.6666666666666665",
+			opts:  tagOptions{"format": "fraction"},
+			want:  "-8/3",
+		},
+		{
+			name:  "fraction denominator option",
+			value: "3.14159",
+			opts:  tagOptions{"format": "fraction", "denominator": "10"},
+			want:  "22/7",
+		},
+		{
+			name:  "fraction exact within denominator",
+			value: "0.0001",
+			opts:  tagOptions{"format": "fraction"},
+			want:  "1/10000",
+		},
+		{
+			name:    "fraction invalid denominator option",
+			value:   "0.5",
+			opts:    tagOptions{"format": "fraction", "denominator": "0"},
+			wantErr: ErrTagInvalidOption,
+		},
+		{
+			name:    "fraction invalid",
+			value:   "abc",
+			opts:    tagOptions{"format": "fraction"},
+			wantErr: ErrInvalidFormat,
+		},
+		{
+			name:  "signedcents trailing sign by default",
+			value: "1550.85",
+			opts:  tagOptions{"format": "signedcents"},
+			want:  "155085+",
+		},
+		{
+			name:  "signedcents leading sign",
+			value: "-1550.8",
+			opts:  tagOptions{"format": "signedcents", "sign": "leading"},
+			want:  "-155080",
+		},
+		{
+			name:  "signedcents no decimals",
+			value: "-42",
+			opts:  tagOptions{"format": "signedcents", "decimals": "0"},
+			want:  "42-",
+		},
+		{
+			name:    "signedcents too many decimals",
+			value:   "1.234",
+			opts:    tagOptions{"format": "signedcents"},
+			wantErr: ErrInvalidFormat,
+		},
+		{
+			name:    "signedcents invalid sign position",
+			value:   "1.23",
+			opts:    tagOptions{"format": "signedcents", "sign": "middle"},
+			wantErr: ErrTagInvalidOption,
+		},
+		{
+			name:  "overpunch positive",
+			value: "1550.85",
+			opts:  tagOptions{"format": "overpunch", "decimals": "2"},
+			want:  "15508E",
+		},
+		{
+			name:  "overpunch negative",
+			value: "-15501",
+			opts:  tagOptions{"format": "overpunch"},
+			want:  "1550J",
+		},
+		{
+			name:  "zoned positive",
+			value: "1550.85",
+			opts:  tagOptions{"format": "zoned", "decimals": "2"},
+			want:  "155085",
+		},
+		{
+			name:  "zoned negative",
+			value: "-1550.85",
+			opts:  tagOptions{"format": "zoned", "decimals": "2"},
+			want:  "15508u",
+		},
+		{
+			name:    "zoned not a number",
+			value:   "true",
+			opts:    tagOptions{"format": "zoned"},
+			wantErr: ErrInvalidFormat,
+		},
+		{
+			name:  "comp3 positive",
+			value: "1550.85",
+			opts:  tagOptions{"format": "comp3", "decimals": "2"},
+			want:  "\x01\x55\x08\x5c",
+		},
+		{
+			name:  "comp3 negative with an even number of digits",
+			value: "-1234",
+			opts:  tagOptions{"format": "comp3"},
+			want:  "\x01\x23\x4d",
+		},
+		{
+			name:    "comp3 not a number",
+			value:   "abc",
+			opts:    tagOptions{"format": "comp3"},
+			wantErr: ErrInvalidFormat,
+		},
+		{
+			name:    "unknown format",
+			value:   "abc",
+			opts:    tagOptions{"format": "base32"},
+			wantErr: ErrTagInvalidOption,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := encodeFormat(tt.value, tt.opts)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+
+			if got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+// reverseCodec stores strings reversed.
+type reverseCodec struct{}
+
+func (reverseCodec) Decode(data []byte, _ FieldOpts) (string, error) {
+	return reverse(string(data)), nil
+}
+
+func (reverseCodec) Encode(value string, _ FieldOpts) ([]byte, error) {
+	return []byte(reverse(value)), nil
+}
+
+func reverse(s string) string {
+	r := []rune(s)
+	for i, j := 0, len(r)-1; i < j; i, j = i+1, j-1 {
+		r[i], r[j] = r[j], r[i]
+	}
+	return string(r)
+}
+
+func TestRegisterFormat(t *testing.T) {
+	RegisterFormat("test-reverse", reverseCodec{})
+	defer func() {
+		formatsMu.Lock()
+		delete(formats, "test-reverse")
+		formatsMu.Unlock()
+	}()
+
+	type testStruct struct {
+		Name  string `range:"0,5,format=test-reverse"`
+		Count int    `range:"5,8,format=test-reverse"`
+	}
+
+	var v testStruct
+	if err := Unmarshal([]byte("cba  021"), &v); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+
+	if v.Name != "abc" || v.Count != 120 {
+		t.Errorf("Expected {abc 120}, got %+v", v)
+	}
+
+	got, err := Marshal(v)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	if want := "cba  021"; string(got) != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+
+	t.Run("nil codec", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("Expected RegisterFormat to panic")
+			}
+		}()
+
+		RegisterFormat("test-nil", nil)
+	})
+}
+
+func TestMarshalFormatRoundTrip(t *testing.T) {
+	type testStruct struct {
+		Name      string  `range:"0,12,format=urlencoded"`
+		Width     float64 `range:"12,17,format=fraction"`
+		Credit    float64 `range:"17,28,format=signedcents"`
+		Debit     float64 `range:"28,39,format=signedcents,sign=leading"`
+		Overpunch int     `range:"39,45,format=overpunch"`
+		Zoned     float64 `range:"45,51,format=zoned,decimals=2"`
+	}
+
+	line := "A%2CB+%26+C 003/80000155085+-000000125000155J00050u"
+
+	var v testStruct
+	if err := Unmarshal([]byte(line), &v); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+
+	want := testStruct{
+		Name:      "A,B & C",
+		Width:     0.375,
+		Credit:    1550.85,
+		Debit:     -12.5,
+		Overpunch: -1551,
+		Zoned:     -5.05,
+	}
+	if v != want {
+		t.Fatalf("Expected %+v, got %+v", want, v)
+	}
+
+	got, err := Marshal(v)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	if string(got) != line {
+		t.Errorf("Expected %q, got %q", line, got)
+	}
+}
+
+func TestMarshalFractionRoundTrip(t *testing.T) {
+	type testStruct struct {
+		Third  float64 `range:"0,5,format=fraction"`
+		Eighth float32 `range:"5,10,format=fraction"`
+	}
+
+	line := "001/3007/8"
+
+	var v testStruct
+	if err := Unmarshal([]byte(line), &v); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+
+	got, err := Marshal(v)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	if string(got) != line {
+		t.Errorf("Expected %q, got %q", line, got)
+	}
+}
+
+func TestMarshalFormatFloatRounding(t *testing.T) {
+	type testStruct struct {
+		Credit    float64 `range:"0,8,format=signedcents"`
+		Overpunch float64 `range:"8,14,format=overpunch,decimals=2"`
+		Zoned     float32 `range:"14,20,format=zoned,decimals=3"`
+		Exact     float64 `range:"20,28,format=signedcents,decimals=3"`
+	}
+
+	// Amounts computed at runtime carry binary rounding noise,
+	// e.g. 0.1+0.2 is 0.30000000000000004
+	a, b := 0.1, 0.2
+	got, err := Marshal(testStruct{Credit: a + b, Overpunch: -(a + b), Zoned: 1.1, Exact: 0.125})
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	if want := "0000030+00003}0011000000125+"; string(got) != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
+
+func TestComp3RoundTrip(t *testing.T) {
+	type testStruct struct {
+		Amount  int     `range:"0,3,format=comp3"`
+		Balance float64 `range:"3,7,format=comp3,decimals=2"`
+		Small   int     `range:"7,9,format=comp3"`
+	}
+
+	// 0x20 bytes are packed digits, which must not be trimmed as padding
+	line := "\x20\x12\x3c\x00\x01\x23\x4d\x20\x1c"
+
+	var v testStruct
+	if err := Unmarshal([]byte(line), &v); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+
+	if v.Amount != 20123 {
+		t.Errorf("Expected v.Amount to be 20123, got %d", v.Amount)
+	}
+
+	if v.Balance != -12.34 {
+		t.Errorf("Expected v.Balance to be -12.34, got %f", v.Balance)
+	}
+
+	if v.Small != 201 {
+		t.Errorf("Expected v.Small to be 201, got %d", v.Small)
+	}
+
+	got, err := Marshal(v)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	if string(got) != line {
+		t.Errorf("Expected %q, got %q", line, got)
+	}
+}
+
+func TestUnmarshalURLEncoded(t *testing.T) {
+	type testStruct struct {
+		Name string `range:"0,12,format=urlencoded"`
+	}
+
+	var v testStruct
+	if err := Unmarshal([]byte("A%2CB+%26+C "), &v); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+
+	if v.Name != "A,B & C" {
+		t.Errorf("Expected v.Name to be 'A,B & C', got %q", v.Name)
+	}
+
+	err := Unmarshal([]byte("A%ZZ        "), &v)
+	if !errors.Is(err, ErrInvalidFormat) {
+		t.Fatalf("Expected ErrInvalidFormat, got %v", err)
+	}
+
+	if want := "field Name"; !strings.Contains(err.Error(), want) {
+		t.Errorf("Expected error to contain %q, got %q", want, err.Error())
+	}
+}
+
+func TestUnmarshalFraction(t *testing.T) {
+	type testStruct struct {
+		Width  float64 `range:"0,5,format=fraction"`
+		Height float32 `range:"5,10,format=fraction"`
+	}
+
+	var v testStruct
+	if err := Unmarshal([]byte(" 3/8   12"), &v); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+
+	if v.Width != 0.375 {
+		t.Errorf("Expected v.Width to be 0.375, got %f", v.Width)
+	}
+
+	if v.Height != 12 {
+		t.Errorf("Expected v.Height to be 12, got %f", v.Height)
+	}
+}
+
+func TestUnmarshalSignedCents(t *testing.T) {
+	type testStruct struct {
+		Credit float64 `range:"0,11,format=signedcents,decimals=2"`
+		Debit  float64 `range:"11,22,format=signedcents"`
+		Units  int     `range:"22,-1,format=signedcents,decimals=0"`
+	}
+
+	var v testStruct
+	if err := Unmarshal([]byte("0000155085+-00000012500042-"), &v); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+
+	if v.Credit != 1550.85 {
+		t.Errorf("Expected v.Credit to be 1550.85, got %f", v.Credit)
+	}
+
+	if v.Debit != -12.5 {
+		t.Errorf("Expected v.Debit to be -12.5, got %f", v.Debit)
+	}
+
+	if v.Units != -42 {
+		t.Errorf("Expected v.Units to be -42, got %d", v.Units)
+	}
+}
@@ -0,0 +1,74 @@
+package fixedlength
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+var ErrInvalidChildField = errors.New("fixedlength: invalid child field")
+
+// DecodeGroup decodes a group of lines made of a header followed by its members.
+// The first line is unmarshaled into parent, which must be a non-nil pointer
+// to a struct, and each of the remaining lines is unmarshaled into a new value
+// of childProto's type and stored in parent's childField slice, replacing
+// its previous contents. The slice may hold either values or pointers of
+// childProto's type. The arguments are validated before parent is modified.
+func DecodeGroup(lines [][]byte, parent any, childField string, childProto any) error {
+	rv := reflect.ValueOf(parent)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return InvalidUnmarshalError{reflect.TypeOf(parent)}
+	}
+
+	// Validate the arguments before touching parent
+	children := rv.Elem().FieldByName(childField)
+	if !children.IsValid() || children.Kind() != reflect.Slice {
+		return fmt.Errorf("%w: %s is not a slice field", ErrInvalidChildField, childField)
+	}
+
+	if !children.CanSet() {
+		return fmt.Errorf("%w: %s is unexported", ErrInvalidChildField, childField)
+	}
+
+	childType := reflect.TypeOf(childProto)
+	if childType != nil && childType.Kind() == reflect.Pointer {
+		childType = childType.Elem()
+	}
+
+	if childType == nil || childType.Kind() != reflect.Struct {
+		return fmt.Errorf("%w: %v is not a struct", ErrInvalidChildField, childType)
+	}
+
+	elemType := children.Type().Elem()
+	isPointer := elemType.Kind() == reflect.Pointer
+	if elemType != childType && (!isPointer || elemType.Elem() != childType) {
+		return fmt.Errorf("%w: %s cannot hold %v", ErrInvalidChildField, childField, childType)
+	}
+
+	if len(lines) == 0 {
+		return nil
+	}
+
+	if err := Unmarshal(lines[0], parent); err != nil {
+		return fmt.Errorf("%w: line 0", err)
+	}
+
+	// The members replace whatever the slice held before
+	members := reflect.MakeSlice(children.Type(), 0, len(lines)-1)
+	for i, line := range lines[1:] {
+		child := reflect.New(childType)
+		if err := Unmarshal(line, child.Interface()); err != nil {
+			return fmt.Errorf("%w: line %d", err, i+1)
+		}
+
+		if !isPointer {
+			child = child.Elem()
+		}
+
+		members = reflect.Append(members, child)
+	}
+
+	children.Set(members)
+
+	return nil
+}
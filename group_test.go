@@ -0,0 +1,154 @@
+package fixedlength
+
+import (
+	"errors"
+	"testing"
+)
+
+type groupDetail struct {
+	Item   string `range:"0,5"`
+	Amount int    `range:"5,-1"`
+}
+
+func TestDecodeGroup(t *testing.T) {
+	type header struct {
+		ID      string `range:"0,4"`
+		Count   int    `range:"4,-1"`
+		Details []groupDetail
+	}
+
+	type pointerHeader struct {
+		ID      string `range:"0,4"`
+		Details []*groupDetail
+	}
+
+	lines := [][]byte{
+		[]byte("H0012"),
+		[]byte("APPLE10"),
+		[]byte("PEAR 250"),
+	}
+
+	t.Run("value children", func(t *testing.T) {
+		var h header
+		if err := DecodeGroup(lines, &h, "Details", groupDetail{}); err != nil {
+			t.Fatalf("DecodeGroup failed: %v", err)
+		}
+
+		if h.ID != "H001" || h.Count != 2 {
+			t.Errorf("Expected header {H001 2}, got {%s %d}", h.ID, h.Count)
+		}
+
+		if len(h.Details) != 2 {
+			t.Fatalf("Expected 2 details, got %d", len(h.Details))
+		}
+
+		if h.Details[1].Item != "PEAR" || h.Details[1].Amount != 250 {
+			t.Errorf("Expected detail {PEAR 250}, got %+v", h.Details[1])
+		}
+	})
+
+	t.Run("replaces existing children", func(t *testing.T) {
+		h := header{Details: []groupDetail{{Item: "OLD"}}}
+		if err := DecodeGroup(lines, &h, "Details", groupDetail{}); err != nil {
+			t.Fatalf("DecodeGroup failed: %v", err)
+		}
+
+		if len(h.Details) != 2 || h.Details[0].Item != "APPLE" {
+			t.Errorf("Expected details [APPLE PEAR], got %+v", h.Details)
+		}
+	})
+
+	t.Run("pointer children", func(t *testing.T) {
+		var h pointerHeader
+		if err := DecodeGroup(lines, &h, "Details", &groupDetail{}); err != nil {
+			t.Fatalf("DecodeGroup failed: %v", err)
+		}
+
+		if len(h.Details) != 2 {
+			t.Fatalf("Expected 2 details, got %d", len(h.Details))
+		}
+
+		if h.Details[0].Item != "APPLE" || h.Details[0].Amount != 10 {
+			t.Errorf("Expected detail {APPLE 10}, got %+v", *h.Details[0])
+		}
+	})
+}
+
+func TestDecodeGroupError(t *testing.T) {
+	type header struct {
+		ID      string `range:"0,4"`
+		Details []groupDetail
+	}
+
+	lines := [][]byte{
+		[]byte("H001"),
+		[]byte("APPLEXX"),
+	}
+
+	t.Run("invalid child line", func(t *testing.T) {
+		var h header
+		err := DecodeGroup(lines, &h, "Details", groupDetail{})
+		if !errors.Is(err, ErrInvalidIntValue) {
+			t.Errorf("Expected ErrInvalidIntValue, got %v", err)
+		}
+	})
+
+	t.Run("missing child field", func(t *testing.T) {
+		var h header
+		err := DecodeGroup(lines, &h, "Items", groupDetail{})
+		if !errors.Is(err, ErrInvalidChildField) {
+			t.Errorf("Expected ErrInvalidChildField, got %v", err)
+		}
+
+		// Invalid arguments leave the parent untouched
+		if h.ID != "" {
+			t.Errorf("Expected h.ID to be empty, got %q", h.ID)
+		}
+	})
+
+	t.Run("mismatched child type", func(t *testing.T) {
+		var h header
+		err := DecodeGroup(lines, &h, "Details", header{})
+		if !errors.Is(err, ErrInvalidChildField) {
+			t.Errorf("Expected ErrInvalidChildField, got %v", err)
+		}
+	})
+
+	t.Run("unexported child field", func(t *testing.T) {
+		var h struct {
+			ID      string `range:"0,4"`
+			details []groupDetail
+		}
+		err := DecodeGroup(lines, &h, "details", groupDetail{})
+		if !errors.Is(err, ErrInvalidChildField) {
+			t.Errorf("Expected ErrInvalidChildField, got %v", err)
+		}
+	})
+
+	t.Run("non-struct child", func(t *testing.T) {
+		var h struct {
+			ID     string `range:"0,4"`
+			Counts []int
+		}
+		err := DecodeGroup(lines, &h, "Counts", 0)
+		if !errors.Is(err, ErrInvalidChildField) {
+			t.Errorf("Expected ErrInvalidChildField, got %v", err)
+		}
+	})
+
+	t.Run("non-pointer parent", func(t *testing.T) {
+		var h header
+		err := DecodeGroup(lines, h, "Details", groupDetail{})
+		if !errors.As(err, &InvalidUnmarshalError{}) {
+			t.Errorf("Expected InvalidUnmarshalError, got %v", err)
+		}
+	})
+
+	t.Run("non-struct parent", func(t *testing.T) {
+		var n int
+		err := DecodeGroup(lines, &n, "Details", groupDetail{})
+		if !errors.As(err, &InvalidUnmarshalError{}) {
+			t.Errorf("Expected InvalidUnmarshalError, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,72 @@
+package fixedlength
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+var ErrInvalidKindValue = errors.New("fixedlength: kind handler value cannot be assigned to field")
+
+// A KindHandler parses the trimmed content of a field into a value that
+// is assigned to the field. The value must be convertible to the field's type.
+type KindHandler func(value string, opts FieldOpts) (reflect.Value, error)
+
+var (
+	kindsMu sync.RWMutex
+	kinds   = map[reflect.Kind]KindHandler{}
+)
+
+// RegisterKind overrides how fields of kind are parsed by Unmarshal, e.g. to
+// accept a comma as the decimal separator of every float64 field:
+//
+//	fixedlength.RegisterKind(reflect.Float64, func(value string, _ fixedlength.FieldOpts) (reflect.Value, error) {
+//		f, err := strconv.ParseFloat(strings.ReplaceAll(value, ",", "."), 64)
+//		return reflect.ValueOf(f), err
+//	})
+//
+// When several parsers apply to a field, types registered with RegisterEnum
+// take precedence, then types implementing [Unmarshaler], then kind handlers,
+// and finally the built-in parsing of the kind. (Without a kind handler,
+// named basic types such as `type Amount float64` are parsed by their kind
+// even if they implement Unmarshaler.) Nested structs are decoded
+// field by field, so struct kind handlers are never used. Kind handlers do
+// not affect Marshal.
+//
+// Registering a kind again replaces its handler. RegisterKind panics if
+// handler is nil.
+func RegisterKind(kind reflect.Kind, handler KindHandler) {
+	if handler == nil {
+		panic("fixedlength: RegisterKind handler is nil")
+	}
+
+	kindsMu.Lock()
+	defer kindsMu.Unlock()
+
+	kinds[kind] = handler
+}
+
+// lookupKind returns the handler registered for kind, if any.
+func lookupKind(kind reflect.Kind) (KindHandler, bool) {
+	kindsMu.RLock()
+	defer kindsMu.RUnlock()
+
+	handler, ok := kinds[kind]
+	return handler, ok
+}
+
+// setKindValue sets field to the value handler parses from value.
+func setKindValue(field reflect.Value, handler KindHandler, value string, opts tagOptions) error {
+	v, err := handler(value, FieldOpts(opts))
+	if err != nil {
+		return err
+	}
+
+	if !v.IsValid() || !v.Type().ConvertibleTo(field.Type()) {
+		return fmt.Errorf("%w: %v for %v", ErrInvalidKindValue, v, field.Type())
+	}
+
+	field.Set(v.Convert(field.Type()))
+	return nil
+}
@@ -0,0 +1,123 @@
+package fixedlength
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// registerTestKind registers handler for kind until the test ends.
+func registerTestKind(t *testing.T, kind reflect.Kind, handler KindHandler) {
+	t.Helper()
+
+	previous, ok := lookupKind(kind)
+	t.Cleanup(func() {
+		kindsMu.Lock()
+		defer kindsMu.Unlock()
+
+		if ok {
+			kinds[kind] = previous
+		} else {
+			delete(kinds, kind)
+		}
+	})
+
+	RegisterKind(kind, handler)
+}
+
+type kindComplex complex128
+
+func (c *kindComplex) Unmarshal(data []byte) error {
+	*c = kindComplex(complex(float64(len(data)), 0))
+	return nil
+}
+
+func TestRegisterKind(t *testing.T) {
+	registerTestKind(t, reflect.Complex128, func(value string, _ FieldOpts) (reflect.Value, error) {
+		c, err := strconv.ParseComplex(value, 128)
+		return reflect.ValueOf(c), err
+	})
+
+	type testStruct struct {
+		Value complex128  `range:"0,6"`
+		Other kindComplex `range:"6,9"`
+	}
+
+	var v testStruct
+	if err := Unmarshal([]byte("1+2i  abc"), &v); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+
+	if v.Value != 1+2i {
+		t.Errorf("Expected v.Value to be (1+2i), got %v", v.Value)
+	}
+
+	// Unmarshaler takes precedence over kind handlers
+	if v.Other != 3 {
+		t.Errorf("Expected v.Other to be set by its Unmarshaler, got %v", v.Other)
+	}
+
+	if err := Unmarshal([]byte("1+xi  abc"), &v); !errors.Is(err, strconv.ErrSyntax) {
+		t.Errorf("Expected the handler's error, got %v", err)
+	}
+}
+
+func TestRegisterKindOverride(t *testing.T) {
+	registerTestKind(t, reflect.Float64, func(value string, _ FieldOpts) (reflect.Value, error) {
+		f, err := strconv.ParseFloat(strings.ReplaceAll(value, ",", "."), 64)
+		return reflect.ValueOf(f), err
+	})
+	registerTestKind(t, reflect.Int, func(string, FieldOpts) (reflect.Value, error) {
+		return reflect.ValueOf(-1), nil
+	})
+
+	type testStruct struct {
+		Amount float64   `range:"0,7"`
+		Color  enumColor `range:"7,10"`
+		Count  int       `range:"10,12"`
+	}
+
+	var v testStruct
+	if err := Unmarshal([]byte("1550,85RED42"), &v); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+
+	if v.Amount != 1550.85 {
+		t.Errorf("Expected v.Amount to be 1550.85, got %f", v.Amount)
+	}
+
+	// Registered enums take precedence over kind handlers
+	if v.Color != enumRed {
+		t.Errorf("Expected v.Color to be %d, got %d", enumRed, v.Color)
+	}
+
+	if v.Count != -1 {
+		t.Errorf("Expected v.Count to be -1, got %d", v.Count)
+	}
+}
+
+func TestRegisterKindInvalidValue(t *testing.T) {
+	registerTestKind(t, reflect.Complex64, func(value string, _ FieldOpts) (reflect.Value, error) {
+		return reflect.ValueOf(value), nil
+	})
+
+	var v struct {
+		Value complex64 `range:"0,3"`
+	}
+
+	if err := Unmarshal([]byte("abc"), &v); !errors.Is(err, ErrInvalidKindValue) {
+		t.Errorf("Expected ErrInvalidKindValue, got %v", err)
+	}
+}
+
+func TestRegisterKindPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Expected RegisterKind to panic with a nil handler")
+		}
+	}()
+
+	RegisterKind(reflect.Float64, nil)
+}
@@ -0,0 +1,369 @@
+package fixedlength
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var ErrValueTooLong = errors.New("fixedlength: value exceeds field width")
+
+// Marshaler is the interface implemented by types
+// that can marshal themselves into a fixed-length field.
+type Marshaler interface {
+	MarshalFixed() ([]byte, error)
+}
+
+var marshalerType = reflect.TypeOf((*Marshaler)(nil)).Elem()
+
+// implementsMarshaler checks if a field implements the Marshaler interface
+func implementsMarshaler(val reflect.Value) bool {
+	if !val.IsValid() {
+		return false
+	}
+
+	if val.Type().Implements(marshalerType) {
+		return true
+	}
+
+	if val.CanAddr() {
+		return val.Addr().Type().Implements(marshalerType)
+	}
+
+	return false
+}
+
+// InvalidMarshalError describes an invalid argument passed to [Marshal].
+// (The argument to [Marshal] must be a struct or a non-nil pointer to a struct.)
+type InvalidMarshalError struct {
+	Type reflect.Type
+}
+
+func (e InvalidMarshalError) Error() string {
+	if e.Type == nil {
+		return "range: Marshal(nil)"
+	}
+	if e.Type.Kind() == reflect.Pointer && e.Type.Elem().Kind() == reflect.Struct {
+		return "range: Marshal(nil " + e.Type.String() + ")"
+	}
+	return "range: Marshal(non-struct " + e.Type.String() + ")"
+}
+
+// Marshal returns the fixed-length encoding of v, the inverse of [Unmarshal].
+// v must be a struct or a pointer to a struct, and its fields are placed in
+// the ranges given by their `range:"<start>,<end>"` tags.
+//
+// Each value is padded to the width of its range: numbers are right aligned
+// and padded with zeros, everything else is left aligned and padded with
+// spaces. The pad and align tag options override the padding character and
+// the alignment, e.g. `range:"37,45,pad=0,align=right"`. Floats use their
+// shortest representation unless the prec tag option sets their decimals.
+// Nil pointers produce a blank field, and bytes not covered by any field
+// are spaces. A field ending in -1 takes the natural length of its value.
+// Values wider than their range fail with ErrValueTooLong.
+//
+// Types implementing [Marshaler] format themselves, and fields with a format
+// option are encoded by its [FormatCodec] before being padded.
+// Marshal will encode nested structs recursively.
+func Marshal(v any) ([]byte, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, InvalidMarshalError{reflect.TypeOf(v)}
+	}
+
+	// Work on an addressable copy so pointer receiver methods can be used
+	if !rv.CanAddr() {
+		addressable := reflect.New(rv.Type()).Elem()
+		addressable.Set(rv)
+		rv = addressable
+	}
+
+	var buf []byte
+	if err := marshalStruct(&buf, rv, "", 0); err != nil {
+		return nil, err
+	}
+
+	return buf, nil
+}
+
+// An Encoder writes fixed-length records to an output stream.
+type Encoder struct {
+	w io.Writer
+}
+
+// NewEncoder returns a new encoder that writes to w.
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: w}
+}
+
+// WriteRecord writes the [Marshal] encoding of v to the stream,
+// followed by a newline character.
+func (e *Encoder) WriteRecord(v any) error {
+	data, err := Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	_, err = e.w.Write(append(data, '\n'))
+	return err
+}
+
+// marshalStruct writes the fields of the struct rv into buf, growing it
+// as needed. rv is nested depth levels below the value passed to Marshal.
+func marshalStruct(buf *[]byte, rv reflect.Value, path string, depth int) error {
+	if depth > MaxDepth {
+		return fmt.Errorf("%w: %s", ErrMaxDepthExceeded, path)
+	}
+
+	for i := 0; i < rv.NumField(); i++ {
+		field := rv.Field(i)
+		structField := rv.Type().Field(i)
+
+		name := structField.Name
+		if path != "" {
+			name = path + "." + name
+		}
+
+		// Recursively encode the struct, unless it handles its own format
+		if field.Kind() == reflect.Struct && !implementsMarshaler(field) && !implementsUnmarshaler(field) {
+			if err := marshalStruct(buf, field, name, depth+1); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		tag := structField.Tag.Get("range")
+		if tag == "" || tag == lineNoTag {
+			continue
+		}
+
+		if err := marshalField(buf, field, name, tag); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// marshalField formats field and writes it into buf at the range described by tag.
+func marshalField(buf *[]byte, field reflect.Value, name, tag string) error {
+	start, end, err := parseRange(tag)
+	if err != nil {
+		return fmt.Errorf("%w: field %s", err, name)
+	}
+
+	opts := parseTagOptions(tag)
+
+	value, numeric, err := formatFieldValue(field, opts)
+	if err != nil {
+		return fmt.Errorf("%w: field %s", err, name)
+	}
+
+	// Nil pointers are left blank rather than encoded or padded
+	pad, right := " ", false
+	if field.Kind() != reflect.Pointer || !field.IsNil() {
+		value, err = encodeAlias(value, opts)
+		if err != nil {
+			return fmt.Errorf("%w: field %s", err, name)
+		}
+
+		value, err = encodeFormat(value, opts)
+		if err != nil {
+			return fmt.Errorf("%w: field %s", err, name)
+		}
+
+		pad, right, err = parsePadding(opts, numeric)
+		if err != nil {
+			return fmt.Errorf("%w: field %s", err, name)
+		}
+	}
+
+	// -1 is used to indicate that the value's own length should be used,
+	// in which case an empty value takes no space
+	natural := end == -1
+	if natural {
+		end = start + len(value)
+	}
+
+	if start < 0 || start > end || (start == end && !natural) {
+		return fmt.Errorf("%w: %s: field %s", ErrTagInefectualRange, tag, name)
+	}
+
+	width := end - start
+	if len(value) > width {
+		return fmt.Errorf("%w: field %s: %q is %d bytes wide, range %s allows %d", ErrValueTooLong, name, value, len(value), tag, width)
+	}
+
+	if end > len(*buf) {
+		*buf = append(*buf, strings.Repeat(" ", end-len(*buf))...)
+	}
+
+	copy((*buf)[start:end], padValue(value, width, pad, right))
+
+	return nil
+}
+
+// formatFieldValue returns the textual representation of a struct field
+// and whether it is a number. Nil pointers are formatted as an empty string.
+// Booleans with bool=numeric are written as the numbers 1 and 0.
+// Floats are written in their shortest form unless the prec option (or the
+// implied decimals of their format) sets the number of decimals, e.g. 1200
+// with prec=2 is "1200.00".
+func formatFieldValue(field reflect.Value, opts tagOptions) (string, bool, error) {
+	if field.Kind() == reflect.Pointer {
+		if field.IsNil() {
+			return "", false, nil
+		}
+		field = field.Elem()
+	}
+
+	if e, ok := lookupEnum(field.Type()); ok {
+		name, err := e.name(field)
+		return name, false, err
+	}
+
+	if implementsMarshaler(field) {
+		m, ok := field.Interface().(Marshaler)
+		if !ok {
+			m = field.Addr().Interface().(Marshaler)
+		}
+
+		data, err := m.MarshalFixed()
+		return string(data), false, err
+	}
+
+	switch field.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(field.Int(), 10), true, nil
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(field.Uint(), 10), true, nil
+
+	case reflect.Float32, reflect.Float64:
+		prec, err := parsePrecision(opts)
+		if err != nil {
+			return "", false, err
+		}
+		return strconv.FormatFloat(field.Float(), 'f', prec, field.Type().Bits()), true, nil
+
+	case reflect.String:
+		return field.String(), false, nil
+
+	case reflect.Bool:
+		switch mode := opts["bool"]; mode {
+		case "":
+			return strconv.FormatBool(field.Bool()), false, nil
+		case "numeric":
+			if field.Bool() {
+				return "1", true, nil
+			}
+			return "0", true, nil
+		default:
+			return "", false, fmt.Errorf("%w: bool=%s", ErrTagInvalidOption, mode)
+		}
+
+	default:
+		return "", false, fmt.Errorf("%w: %s", ErrUnsupportedKind, field.Kind())
+	}
+}
+
+// parsePrecision returns the number of decimals floats are formatted with.
+// It is set by the prec option and otherwise defaults to the implied decimals
+// of the field's format, if any, or -1 for the shortest representation.
+func parsePrecision(opts tagOptions) (int, error) {
+	option, ok := opts["prec"]
+	if !ok {
+		codec, ok, err := lookupFormat(opts)
+		if err != nil {
+			return 0, err
+		}
+
+		if d, isDecimals := codec.(decimalsCodec); ok && isDecimals {
+			return d.decimals(FieldOpts(opts))
+		}
+
+		return -1, nil
+	}
+
+	prec, err := strconv.Atoi(option)
+	if err != nil || prec < 0 {
+		return 0, fmt.Errorf("%w: prec=%s", ErrTagInvalidOption, option)
+	}
+
+	return prec, nil
+}
+
+// parsePadding returns the padding character and alignment set by the pad
+// and align options. Numbers default to being right aligned and padded with
+// zeros, everything else to being left aligned and padded with spaces, unless
+// the field's format requires its own padding.
+func parsePadding(opts tagOptions, numeric bool) (string, bool, error) {
+	codec, ok, err := lookupFormat(opts)
+	if err != nil {
+		return "", false, err
+	}
+
+	var defaultPad string
+	defaultRight := numeric
+	if b, isBinary := codec.(binaryCodec); ok && isBinary {
+		defaultPad, defaultRight = b.padding()
+	}
+
+	var right bool
+	switch align := opts["align"]; align {
+	case "":
+		right = defaultRight
+	case "left":
+		right = false
+	case "right":
+		right = true
+	default:
+		return "", false, fmt.Errorf("%w: align=%s", ErrTagInvalidOption, align)
+	}
+
+	pad, ok := opts["pad"]
+	switch {
+	case ok:
+	case defaultPad != "":
+		pad = defaultPad
+	case numeric && right:
+		pad = "0"
+	default:
+		pad = " "
+	}
+
+	// Ranges are measured in bytes, so the padding must be a single byte
+	if len(pad) != 1 {
+		return "", false, fmt.Errorf("%w: pad=%s", ErrTagInvalidOption, pad)
+	}
+
+	return pad, right, nil
+}
+
+// padValue pads value to width with pad, on the left if right is set and on
+// the right otherwise. Values padded with zeros on the left keep their sign
+// in front, e.g. "-42" is "-0042".
+func padValue(value string, width int, pad string, right bool) string {
+	n := width - len(value)
+	if n <= 0 {
+		return value
+	}
+
+	if !right {
+		return value + strings.Repeat(pad, n)
+	}
+
+	sign := ""
+	if pad == "0" && (strings.HasPrefix(value, "-") || strings.HasPrefix(value, "+")) {
+		sign, value = value[:1], value[1:]
+	}
+
+	return sign + strings.Repeat(pad, n) + value
+}
@@ -0,0 +1,366 @@
+package fixedlength
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+type marshalBirthDate struct {
+	time.Time
+}
+
+var (
+	_ Unmarshaler = (*marshalBirthDate)(nil)
+	_ Marshaler   = (*marshalBirthDate)(nil)
+)
+
+func (d *marshalBirthDate) Unmarshal(data []byte) error {
+	t, err := time.Parse("20060102", string(data))
+	if err != nil {
+		return err
+	}
+
+	d.Time = t
+	return nil
+}
+
+func (d *marshalBirthDate) MarshalFixed() ([]byte, error) {
+	return []byte(d.Format("20060102")), nil
+}
+
+type marshalPerson struct {
+	FullName  string           `range:"0,20"`
+	BirthDate marshalBirthDate `range:"20,28"`
+	SSN       string           `range:"28,37"`
+	Income    float64          `range:"37,-1,prec=2"`
+}
+
+func TestMarshalRoundTrip(t *testing.T) {
+	// The lines of examples/custom, whose trailing padding is uneven
+	lines := []string{
+		"Olivia Parker       199703221112223331550.85   ",
+		"Liam Evans          19891008444555666675.25   ",
+		"Emma Ward           200307137778889991200.00  ",
+		"Noah Scott          19910601333222555999.99   ",
+		"Amelia Ross         19861127666555444400.45   ",
+	}
+
+	for _, line := range lines {
+		var p marshalPerson
+		if err := Unmarshal([]byte(line), &p); err != nil {
+			t.Fatalf("Unmarshal failed: %v", err)
+		}
+
+		got, err := Marshal(p)
+		if err != nil {
+			t.Fatalf("Marshal failed: %v", err)
+		}
+
+		// Income ends in -1 and takes its natural length, so the trailing
+		// padding of the original line cannot be reproduced
+		if want := strings.TrimRight(line, " "); string(got) != want {
+			t.Errorf("expected %q, got %q", want, got)
+		}
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	type nested struct {
+		Code string `range:"0,3"`
+	}
+
+	type testStruct struct {
+		Nested   nested
+		Count    int      `range:"3,8"`
+		Balance  int      `range:"8,13"`
+		Unsigned uint8    `range:"13,16"`
+		Active   bool     `range:"16,22"`
+		Missing  *int     `range:"22,25"`
+		Present  *float64 `range:"25,30"`
+		Trailing string   `range:"32,-1"`
+		Ignored  string
+	}
+
+	rate := 2.5
+	v := testStruct{
+		Nested:   nested{Code: "AB"},
+		Count:    42,
+		Balance:  -7,
+		Unsigned: 255,
+		Active:   true,
+		Present:  &rate,
+		Trailing: "END",
+		Ignored:  "ignored",
+	}
+
+	got, err := Marshal(&v)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	want := "AB 00042-0007255true     002.5  END"
+	if string(got) != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+func TestMarshalNumericBool(t *testing.T) {
+	type testStruct struct {
+		Active  bool `range:"0,3,bool=numeric"`
+		Deleted bool `range:"3,4,bool=numeric"`
+	}
+
+	line := "0010"
+
+	var v testStruct
+	if err := Unmarshal([]byte(line), &v); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+
+	if !v.Active || v.Deleted {
+		t.Fatalf("Expected {true false}, got %+v", v)
+	}
+
+	got, err := Marshal(v)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	if string(got) != line {
+		t.Errorf("expected %q, got %q", line, got)
+	}
+}
+
+func TestMarshalEmptyTrailingField(t *testing.T) {
+	type testStruct struct {
+		Name    string  `range:"0,5"`
+		Notes   string  `range:"5,-1"`
+		Missing *string `range:"5,-1"`
+	}
+
+	got, err := Marshal(testStruct{Name: "Ann"})
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	if want := "Ann  "; string(got) != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+func TestMarshalError(t *testing.T) {
+	t.Run("value too long", func(t *testing.T) {
+		type testStruct struct {
+			Name string `range:"0,3"`
+		}
+
+		_, err := Marshal(testStruct{Name: "Olivia"})
+		if !errors.Is(err, ErrValueTooLong) {
+			t.Fatalf("Expected ErrValueTooLong, got %v", err)
+		}
+
+		if want := "field Name"; !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected error to contain %q, got %q", want, err.Error())
+		}
+	})
+
+	t.Run("number too long", func(t *testing.T) {
+		type testStruct struct {
+			Count int `range:"0,2"`
+		}
+
+		_, err := Marshal(testStruct{Count: -10})
+		if !errors.Is(err, ErrValueTooLong) {
+			t.Errorf("Expected ErrValueTooLong, got %v", err)
+		}
+	})
+
+	t.Run("unsupported kind", func(t *testing.T) {
+		type testStruct struct {
+			Items []string `range:"0,2"`
+		}
+
+		_, err := Marshal(testStruct{})
+		if !errors.Is(err, ErrUnsupportedKind) {
+			t.Errorf("Expected ErrUnsupportedKind, got %v", err)
+		}
+	})
+
+	t.Run("ineffectual range", func(t *testing.T) {
+		type testStruct struct {
+			Name string `range:"3,3"`
+		}
+
+		_, err := Marshal(testStruct{})
+		if !errors.Is(err, ErrTagInefectualRange) {
+			t.Errorf("Expected ErrTagInefectualRange, got %v", err)
+		}
+	})
+
+	t.Run("marshaler error", func(t *testing.T) {
+		errMarshal := errors.New("marshal failed")
+		_, err := Marshal(struct {
+			A failingMarshaler `range:"0,2"`
+		}{A: failingMarshaler{errMarshal}})
+		if !errors.Is(err, errMarshal) {
+			t.Errorf("Expected errMarshal, got %v", err)
+		}
+	})
+
+	tests := []struct {
+		name string
+		v    any
+		want string
+	}{
+		{name: "nil", v: nil, want: "range: Marshal(nil)"},
+		{name: "non-struct int", v: 42, want: "range: Marshal(non-struct int)"},
+		{name: "nil *struct", v: (*marshalPerson)(nil), want: "range: Marshal(nil *fixedlength.marshalPerson)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Marshal(tt.v)
+			if err == nil {
+				t.Fatalf("Expected Marshal to fail")
+			}
+
+			if err.Error() != tt.want {
+				t.Errorf("Expected error to be %q, got %q", tt.want, err.Error())
+			}
+		})
+	}
+}
+
+type failingMarshaler struct {
+	err error
+}
+
+func (f failingMarshaler) MarshalFixed() ([]byte, error) {
+	return nil, f.err
+}
+
+func TestMarshalPadding(t *testing.T) {
+	type testStruct struct {
+		Code    string  `range:"0,6,pad=0,align=right"`
+		Name    string  `range:"6,12,align=right"`
+		Count   int     `range:"12,17,align=left"`
+		Amount  float64 `range:"17,25,pad=0,align=right"`
+		Balance int     `range:"25,30,pad= "`
+		Filler  string  `range:"30,34,pad=*"`
+		Missing *int    `range:"34,37,pad=0,align=right"`
+	}
+
+	got, err := Marshal(testStruct{
+		Code:    "AB",
+		Name:    "Ann",
+		Count:   42,
+		Amount:  -1550.85,
+		Balance: -7,
+		Filler:  "x",
+	})
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	want := "0000AB   Ann42   -1550.85   -7x***   "
+	if string(got) != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+
+	t.Run("float precision", func(t *testing.T) {
+		type precStruct struct {
+			Whole  float64 `range:"0,7,prec=2"`
+			Round  float32 `range:"7,12,prec=1"`
+			Digits float64 `range:"12,15,prec=0"`
+		}
+
+		got, err := Marshal(precStruct{Whole: 1200, Round: 2.25, Digits: 42})
+		if err != nil {
+			t.Fatalf("Marshal failed: %v", err)
+		}
+
+		if want := "1200.00002.2042"; string(got) != want {
+			t.Errorf("expected %q, got %q", want, got)
+		}
+	})
+
+	t.Run("invalid options", func(t *testing.T) {
+		tests := []struct {
+			name string
+			v    any
+		}{
+			{name: "align", v: struct {
+				A string `range:"0,3,align=center"`
+			}{}},
+			{name: "multi-byte pad", v: struct {
+				A string `range:"0,3,pad=ab"`
+			}{}},
+			{name: "empty pad", v: struct {
+				A string `range:"0,3,pad="`
+			}{}},
+			{name: "negative prec", v: struct {
+				A float64 `range:"0,3,prec=-1"`
+			}{}},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				if _, err := Marshal(tt.v); !errors.Is(err, ErrTagInvalidOption) {
+					t.Errorf("Expected ErrTagInvalidOption, got %v", err)
+				}
+			})
+		}
+	})
+}
+
+func TestEncoder(t *testing.T) {
+	people := []marshalPerson{
+		{FullName: "Olivia Parker", SSN: "111222333", Income: 1550.85},
+		{FullName: "Liam Evans", SSN: "444555666", Income: 675.25},
+	}
+
+	var out strings.Builder
+	enc := NewEncoder(&out)
+	for _, p := range people {
+		p.BirthDate.Time = time.Date(1997, 3, 22, 0, 0, 0, 0, time.UTC)
+		if err := enc.WriteRecord(&p); err != nil {
+			t.Fatalf("WriteRecord failed: %v", err)
+		}
+	}
+
+	want := "Olivia Parker       199703221112223331550.85\n" +
+		"Liam Evans          19970322444555666675.25\n"
+	if out.String() != want {
+		t.Errorf("expected %q, got %q", want, out.String())
+	}
+
+	if err := enc.WriteRecord(42); err == nil {
+		t.Errorf("Expected WriteRecord to fail for a non-struct value")
+	}
+}
+
+func TestPadValue(t *testing.T) {
+	tests := []struct {
+		value string
+		width int
+		pad   string
+		right bool
+		want  string
+	}{
+		{value: "abc", width: 5, pad: " ", want: "abc  "},
+		{value: "42", width: 5, pad: "0", right: true, want: "00042"},
+		{value: "-42", width: 5, pad: "0", right: true, want: "-0042"},
+		{value: "1.5", width: 5, pad: "0", right: true, want: "001.5"},
+		{value: "-42", width: 5, pad: " ", right: true, want: "  -42"},
+		{value: "abc", width: 5, pad: "*", right: true, want: "**abc"},
+		{value: "abc", width: 3, pad: " ", want: "abc"},
+	}
+
+	for _, tt := range tests {
+		if got := padValue(tt.value, tt.width, tt.pad, tt.right); got != tt.want {
+			t.Errorf("padValue(%q, %d, %q, %v): expected %q, got %q", tt.value, tt.width, tt.pad, tt.right, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package fixedlength
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"reflect"
+	"runtime"
+)
+
+// scanResult is the outcome of unmarshaling a single line.
+type scanResult struct {
+	v   any
+	err error
+}
+
+// scanJob is a line waiting to be unmarshaled by a worker.
+type scanJob struct {
+	line   []byte
+	index  int
+	result chan<- scanResult
+}
+
+// lineNoTag is the range tag of fields that hold the 1-based line number
+// of the record they belong to.
+const lineNoTag = ",lineno"
+
+// ScanParallel reads r line by line and unmarshals each line into a new value
+// of proto's type using a pool of workers. workers <= 0 uses GOMAXPROCS workers.
+//
+// Lines are read sequentially and decoded concurrently, but fn is always called
+// from the calling goroutine in input order with the 0-based line index i and a
+// pointer to the decoded value. Empty lines are skipped but still counted.
+// Integer fields tagged `range:",lineno"` are set to the 1-based line number.
+//
+// ScanParallel stops at the first line, in input order, that fails to decode or
+// for which fn returns an error, and returns that error. Decode errors include
+// the line index. Read errors are returned once every preceding line has been
+// handled. Workers that are still busy when an error is returned exit once
+// r stops producing lines.
+func ScanParallel(r io.Reader, proto any, workers int, fn func(i int, v any) error) error {
+	typ, ok := structType(proto)
+	if !ok {
+		return InvalidUnmarshalError{reflect.TypeOf(proto)}
+	}
+
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	jobs := make(chan scanJob)
+	// pending holds the result channels in input order
+	pending := make(chan chan scanResult, workers)
+	done := make(chan struct{})
+
+	for range workers {
+		go func() {
+			for job := range jobs {
+				v := reflect.New(typ).Interface()
+				err := Unmarshal(job.line, v)
+				if err == nil {
+					err = setLineNumber(v, job.index+1)
+				}
+				job.result <- scanResult{v: v, err: err}
+			}
+		}()
+	}
+
+	var scanErr error
+	go func() {
+		defer close(pending)
+		defer close(jobs)
+
+		scanner := bufio.NewScanner(r)
+		for i := 0; scanner.Scan(); i++ {
+			result := make(chan scanResult, 1)
+			if len(scanner.Bytes()) == 0 {
+				result <- scanResult{}
+			} else {
+				// The scanner reuses its buffer, so each job needs its own copy
+				line := append([]byte(nil), scanner.Bytes()...)
+				select {
+				case jobs <- scanJob{line: line, index: i, result: result}:
+				case <-done:
+					return
+				}
+			}
+
+			select {
+			case pending <- result:
+			case <-done:
+				return
+			}
+		}
+
+		scanErr = scanner.Err()
+	}()
+
+	i := 0
+	for result := range pending {
+		res := <-result
+		if res.err != nil {
+			close(done)
+			return fmt.Errorf("%w: line %d", res.err, i)
+		}
+
+		if res.v != nil {
+			if err := fn(i, res.v); err != nil {
+				close(done)
+				return err
+			}
+		}
+
+		i++
+	}
+
+	// pending is closed after scanErr is set
+	return scanErr
+}
+
+// structType returns the struct type of proto, which may be
+// a struct or a pointer to a struct.
+func structType(proto any) (reflect.Type, bool) {
+	typ := reflect.TypeOf(proto)
+	if typ != nil && typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+
+	return typ, typ != nil && typ.Kind() == reflect.Struct
+}
+
+// setLineNumber sets the fields of the struct pointed to by v, including
+// those of nested structs, that are tagged with lineNoTag to line.
+func setLineNumber(v any, line int) error {
+	return setLineNumberStruct(reflect.ValueOf(v).Elem(), line, "")
+}
+
+func setLineNumberStruct(rv reflect.Value, line int, path string) error {
+	for i := 0; i < rv.NumField(); i++ {
+		field := rv.Field(i)
+		structField := rv.Type().Field(i)
+
+		name := structField.Name
+		if path != "" {
+			name = path + "." + name
+		}
+
+		if field.Kind() == reflect.Struct && !implementsUnmarshaler(field) {
+			if err := setLineNumberStruct(field, line, name); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if structField.Tag.Get("range") != lineNoTag {
+			continue
+		}
+
+		switch field.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if field.OverflowInt(int64(line)) {
+				return fmt.Errorf("%w: line number %d: field %s", ErrInvalidIntValue, line, name)
+			}
+			field.SetInt(int64(line))
+
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			if field.OverflowUint(uint64(line)) {
+				return fmt.Errorf("%w: line number %d: field %s", ErrInvalidUintValue, line, name)
+			}
+			field.SetUint(uint64(line))
+
+		default:
+			return fmt.Errorf("%w: %s: field %s", ErrUnsupportedKind, field.Kind(), name)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,161 @@
+package fixedlength
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+type scanPerson struct {
+	Name string `range:"0,6"`
+	Age  int    `range:"6,-1"`
+}
+
+func TestScanParallel(t *testing.T) {
+	var input strings.Builder
+	for i := range 200 {
+		fmt.Fprintf(&input, "P%-5d%d\n", i, i)
+		if i%50 == 0 {
+			input.WriteString("\n")
+		}
+	}
+
+	var names []string
+	var indexes []int
+	err := ScanParallel(strings.NewReader(input.String()), scanPerson{}, 8, func(i int, v any) error {
+		p := v.(*scanPerson)
+		if p.Name != fmt.Sprintf("P%d", p.Age) {
+			t.Errorf("line %d: mismatched record %+v", i, *p)
+		}
+
+		names = append(names, p.Name)
+		indexes = append(indexes, i)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ScanParallel failed: %v", err)
+	}
+
+	if len(names) != 200 {
+		t.Fatalf("Expected 200 records, got %d", len(names))
+	}
+
+	for n, name := range names {
+		if want := fmt.Sprintf("P%d", n); name != want {
+			t.Fatalf("Expected record %d to be %s, got %s", n, want, name)
+		}
+	}
+
+	// Line 0 is followed by an empty line, which is skipped but counted
+	if indexes[0] != 0 || indexes[1] != 2 {
+		t.Errorf("Expected the first indexes to be [0 2], got %v", indexes[:2])
+	}
+}
+
+type scanAudit struct {
+	Line int `range:",lineno"`
+}
+
+type scanRecord struct {
+	Audit scanAudit
+	Name  string `range:"0,6"`
+	Seq   uint16 `range:",lineno"`
+}
+
+func TestScanParallelLineNumber(t *testing.T) {
+	input := "Ann\nBob\n\nCarl\n"
+
+	var lines []int
+	err := ScanParallel(strings.NewReader(input), scanRecord{}, 2, func(i int, v any) error {
+		r := v.(*scanRecord)
+		if r.Audit.Line != i+1 || int(r.Seq) != i+1 {
+			t.Errorf("line %d: expected line number %d, got %+v", i, i+1, *r)
+		}
+
+		lines = append(lines, r.Audit.Line)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ScanParallel failed: %v", err)
+	}
+
+	// The empty third line is skipped but counted
+	if want := []int{1, 2, 4}; fmt.Sprint(lines) != fmt.Sprint(want) {
+		t.Errorf("Expected line numbers %v, got %v", want, lines)
+	}
+
+	t.Run("ignored by Unmarshal and Marshal", func(t *testing.T) {
+		var r scanRecord
+		if err := Unmarshal([]byte("Ann"), &r); err != nil {
+			t.Fatalf("Unmarshal failed: %v", err)
+		}
+
+		if r.Audit.Line != 0 || r.Seq != 0 {
+			t.Errorf("Expected no line number, got %+v", r)
+		}
+
+		r.Seq = 42
+		got, err := Marshal(r)
+		if err != nil {
+			t.Fatalf("Marshal failed: %v", err)
+		}
+
+		if want := "Ann   "; string(got) != want {
+			t.Errorf("Expected %q, got %q", want, got)
+		}
+	})
+
+	t.Run("unsupported kind", func(t *testing.T) {
+		type testStruct struct {
+			Line string `range:",lineno"`
+		}
+
+		err := ScanParallel(strings.NewReader("Ann\n"), testStruct{}, 1, func(int, any) error { return nil })
+		if !errors.Is(err, ErrUnsupportedKind) {
+			t.Errorf("Expected ErrUnsupportedKind, got %v", err)
+		}
+	})
+}
+
+func TestScanParallelError(t *testing.T) {
+	input := "Ann   30\nBob   XX\nCarl  40\nDan   YY\n"
+
+	t.Run("decode error", func(t *testing.T) {
+		calls := 0
+		err := ScanParallel(strings.NewReader(input), &scanPerson{}, 4, func(i int, v any) error {
+			calls++
+			return nil
+		})
+		if !errors.Is(err, ErrInvalidIntValue) {
+			t.Fatalf("Expected ErrInvalidIntValue, got %v", err)
+		}
+
+		if !strings.Contains(err.Error(), "line 1") {
+			t.Errorf("Expected error to reference line 1, got %q", err.Error())
+		}
+
+		if calls != 1 {
+			t.Errorf("Expected fn to be called once, got %d", calls)
+		}
+	})
+
+	t.Run("callback error", func(t *testing.T) {
+		errStop := errors.New("stop")
+		err := ScanParallel(strings.NewReader(input), scanPerson{}, 4, func(i int, v any) error {
+			return errStop
+		})
+		if !errors.Is(err, errStop) {
+			t.Errorf("Expected errStop, got %v", err)
+		}
+	})
+
+	t.Run("invalid proto", func(t *testing.T) {
+		err := ScanParallel(strings.NewReader(input), 42, 4, func(i int, v any) error {
+			return nil
+		})
+		if !errors.As(err, &InvalidUnmarshalError{}) {
+			t.Errorf("Expected InvalidUnmarshalError, got %v", err)
+		}
+	})
+}
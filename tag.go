@@ -12,6 +12,7 @@ var (
 	ErrTagEmpty              = errors.New("fixedlength: tag is empty")
 	ErrTagInvalidRangeValues = errors.New("fixedlength: invalid range values")
 	ErrTagInvalidUpperBound  = errors.New("fixedlength: invalid upper bound")
+	ErrTagInvalidOption      = errors.New("fixedlength: invalid option")
 )
 
 // parseTag splits a struct field's json tag into its name and
@@ -25,15 +26,9 @@ func parseTag(tag string, upperBound int) (int, int, error) {
 		return 0, 0, fmt.Errorf("%w: %d", ErrTagInvalidUpperBound, upperBound)
 	}
 
-	parts := strings.Split(tag, ",")
-	x, err := strconv.Atoi(parts[0])
-	if err != nil {
-		return 0, 0, errors.Join(ErrTagInvalidRangeValues, err)
-	}
-
-	y, err := strconv.Atoi(parts[1])
+	x, y, err := parseRange(tag)
 	if err != nil {
-		return 0, 0, errors.Join(ErrTagInvalidRangeValues, err)
+		return 0, 0, err
 	}
 
 	start := max(x, 0)
@@ -54,3 +49,46 @@ func parseTag(tag string, upperBound int) (int, int, error) {
 
 	return start, end, nil
 }
+
+// parseRange returns the start and end values declared in the tag,
+// without bounding them to any data length.
+func parseRange(tag string) (int, int, error) {
+	parts := strings.Split(tag, ",")
+	if len(parts) < 2 {
+		return 0, 0, fmt.Errorf("%w: %s", ErrTagInvalidRangeValues, tag)
+	}
+
+	x, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, 0, errors.Join(ErrTagInvalidRangeValues, err)
+	}
+
+	y, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, 0, errors.Join(ErrTagInvalidRangeValues, err)
+	}
+
+	return x, y, nil
+}
+
+// tagOptions holds the key=value options that may follow the range
+// in a struct field's tag, e.g. `range:"0,10,trim=left"`.
+type tagOptions map[string]string
+
+// parseTagOptions returns the options declared after the start and end
+// values of the tag. Options without a value are stored with an empty value.
+func parseTagOptions(tag string) tagOptions {
+	opts := tagOptions{}
+
+	parts := strings.Split(tag, ",")
+	if len(parts) <= 2 {
+		return opts
+	}
+
+	for _, part := range parts[2:] {
+		key, value, _ := strings.Cut(part, "=")
+		opts[key] = value
+	}
+
+	return opts
+}
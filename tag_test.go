@@ -2,6 +2,7 @@ package fixedlength
 
 import (
 	"errors"
+	"reflect"
 	"testing"
 )
 
@@ -113,3 +114,64 @@ func TestParseTag(t *testing.T) {
 		})
 	}
 }
+
+func TestParseTagOptions(t *testing.T) {
+	tests := []struct {
+		name string
+		tag  string
+		want tagOptions
+	}{
+		{
+			name: "no options",
+			tag:  "0,10",
+			want: tagOptions{},
+		},
+		{
+			name: "key value options",
+			tag:  "0,10,trim=left,trimSet= *",
+			want: tagOptions{"trim": "left", "trimSet": " *"},
+		},
+		{
+			name: "option without value",
+			tag:  "0,10,flag",
+			want: tagOptions{"flag": ""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseTagOptions(tt.tag)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestParseRange(t *testing.T) {
+	tests := []struct {
+		name    string
+		tag     string
+		wantX   int
+		wantY   int
+		wantErr error
+	}{
+		{name: "range", tag: "2,5", wantX: 2, wantY: 5},
+		{name: "range with options", tag: "0,-1,trim=left", wantX: 0, wantY: -1},
+		{name: "missing end", tag: "2", wantErr: ErrTagInvalidRangeValues},
+		{name: "non-numeric", tag: "a,5", wantErr: ErrTagInvalidRangeValues},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			x, y, err := parseRange(tt.tag)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+
+			if x != tt.wantX || y != tt.wantY {
+				t.Errorf("expected %d,%d, got %d,%d", tt.wantX, tt.wantY, x, y)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package fixedlength
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+)
+
+var ErrTranscodeType = errors.New("fixedlength: converted record has the wrong type")
+
+// Transcode reads r line by line, unmarshals each line into a new value of
+// srcProto's type, passes a pointer to it to convert and writes the result,
+// marshaled as a line, to w. The value returned by convert must be of
+// dstProto's type, or a pointer to it; returning nil drops the record.
+// Empty lines are skipped, and integer fields of the source tagged
+// `range:",lineno"` are set to the 1-based line number.
+//
+// Transcode stops at the first record that fails to decode, convert or
+// encode, and returns its error with the 0-based line index.
+// See [TranscodeBestEffort] to skip failing records instead.
+func Transcode(r io.Reader, w io.Writer, srcProto, dstProto any, convert func(src any) (any, error)) error {
+	return transcode(r, w, srcProto, dstProto, convert, func(_ int, err error) error {
+		return err
+	})
+}
+
+// TranscodeBestEffort is like [Transcode] but skips the records that fail to
+// decode, convert or encode, and returns their errors keyed by 0-based line
+// index. The returned error is only non-nil when the prototypes are invalid
+// or reading r or writing w fails.
+func TranscodeBestEffort(r io.Reader, w io.Writer, srcProto, dstProto any, convert func(src any) (any, error)) (map[int]error, error) {
+	errs := map[int]error{}
+	err := transcode(r, w, srcProto, dstProto, convert, func(line int, err error) error {
+		errs[line] = err
+		return nil
+	})
+
+	return errs, err
+}
+
+// transcode implements Transcode. Record errors are passed to handleErr along
+// with their line index; if it returns an error transcoding stops and that
+// error is returned, otherwise the record is skipped.
+func transcode(r io.Reader, w io.Writer, srcProto, dstProto any, convert func(src any) (any, error), handleErr func(line int, err error) error) error {
+	srcType, ok := structType(srcProto)
+	if !ok {
+		return InvalidUnmarshalError{reflect.TypeOf(srcProto)}
+	}
+
+	dstType, ok := structType(dstProto)
+	if !ok {
+		return InvalidMarshalError{reflect.TypeOf(dstProto)}
+	}
+
+	bw := bufio.NewWriter(w)
+
+	scanner := bufio.NewScanner(r)
+	for i := 0; scanner.Scan(); i++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		data, err := transcodeLine(scanner.Bytes(), i+1, srcType, dstType, convert)
+		if err != nil {
+			if err := handleErr(i, fmt.Errorf("%w: line %d", err, i)); err != nil {
+				return errors.Join(err, bw.Flush())
+			}
+
+			continue
+		}
+
+		if data == nil {
+			continue
+		}
+
+		if _, err := bw.Write(append(data, '\n')); err != nil {
+			return err
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return errors.Join(err, bw.Flush())
+	}
+
+	return bw.Flush()
+}
+
+// transcodeLine converts a single line, returning nil if convert drops it.
+// lineno is the 1-based line number of the line.
+func transcodeLine(line []byte, lineno int, srcType, dstType reflect.Type, convert func(src any) (any, error)) ([]byte, error) {
+	src := reflect.New(srcType).Interface()
+	if err := Unmarshal(line, src); err != nil {
+		return nil, err
+	}
+
+	if err := setLineNumber(src, lineno); err != nil {
+		return nil, err
+	}
+
+	dst, err := convert(src)
+	if err != nil || dst == nil {
+		return nil, err
+	}
+
+	if typ, _ := structType(dst); typ != dstType {
+		return nil, fmt.Errorf("%w: got %T, want %v", ErrTranscodeType, dst, dstType)
+	}
+
+	return Marshal(dst)
+}
@@ -0,0 +1,147 @@
+package fixedlength
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+type transcodeSource struct {
+	Name string `range:"0,6"`
+	Age  int    `range:"6,9"`
+}
+
+type transcodeTarget struct {
+	Age  int    `range:"0,3"`
+	Name string `range:"3,9"`
+}
+
+func toTranscodeTarget(src any) (any, error) {
+	s := src.(*transcodeSource)
+	if s.Name == "skip" {
+		return nil, nil
+	}
+
+	return transcodeTarget{Age: s.Age, Name: s.Name}, nil
+}
+
+func TestTranscode(t *testing.T) {
+	input := "Ann    30\n\nskip   10\nBob    41\n"
+
+	var out strings.Builder
+	err := Transcode(strings.NewReader(input), &out, transcodeSource{}, &transcodeTarget{}, toTranscodeTarget)
+	if err != nil {
+		t.Fatalf("Transcode failed: %v", err)
+	}
+
+	want := "030Ann   \n041Bob   \n"
+	if out.String() != want {
+		t.Errorf("Expected %q, got %q", want, out.String())
+	}
+}
+
+func TestTranscodeLineNumber(t *testing.T) {
+	type source struct {
+		Name string `range:"0,6"`
+		Line int    `range:",lineno"`
+	}
+
+	type target struct {
+		Line int    `range:"0,3"`
+		Name string `range:"3,9"`
+	}
+
+	input := "Ann\n\nBob\nCarl\n"
+
+	var out strings.Builder
+	err := Transcode(strings.NewReader(input), &out, source{}, target{}, func(src any) (any, error) {
+		s := src.(*source)
+		return target{Line: s.Line, Name: s.Name}, nil
+	})
+	if err != nil {
+		t.Fatalf("Transcode failed: %v", err)
+	}
+
+	want := "001Ann   \n003Bob   \n004Carl  \n"
+	if out.String() != want {
+		t.Errorf("Expected %q, got %q", want, out.String())
+	}
+}
+
+func TestTranscodeError(t *testing.T) {
+	input := "Ann    30\nBob    XX\nCarl   40\n"
+
+	t.Run("decode error", func(t *testing.T) {
+		var out strings.Builder
+		err := Transcode(strings.NewReader(input), &out, transcodeSource{}, transcodeTarget{}, toTranscodeTarget)
+		if !errors.Is(err, ErrInvalidIntValue) {
+			t.Fatalf("Expected ErrInvalidIntValue, got %v", err)
+		}
+
+		if want := "line 1"; !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected error to contain %q, got %q", want, err.Error())
+		}
+
+		// Records before the failing one are still written
+		if want := "030Ann   \n"; out.String() != want {
+			t.Errorf("Expected %q, got %q", want, out.String())
+		}
+	})
+
+	t.Run("convert error", func(t *testing.T) {
+		errConvert := errors.New("convert failed")
+		err := Transcode(strings.NewReader("Ann    30\n"), &strings.Builder{}, transcodeSource{}, transcodeTarget{}, func(any) (any, error) {
+			return nil, errConvert
+		})
+		if !errors.Is(err, errConvert) {
+			t.Errorf("Expected errConvert, got %v", err)
+		}
+	})
+
+	t.Run("wrong type", func(t *testing.T) {
+		err := Transcode(strings.NewReader("Ann    30\n"), &strings.Builder{}, transcodeSource{}, transcodeTarget{}, func(src any) (any, error) {
+			return src, nil
+		})
+		if !errors.Is(err, ErrTranscodeType) {
+			t.Errorf("Expected ErrTranscodeType, got %v", err)
+		}
+	})
+
+	t.Run("invalid prototypes", func(t *testing.T) {
+		err := Transcode(strings.NewReader(input), &strings.Builder{}, 42, transcodeTarget{}, toTranscodeTarget)
+		var unmarshalErr InvalidUnmarshalError
+		if !errors.As(err, &unmarshalErr) {
+			t.Errorf("Expected InvalidUnmarshalError, got %v", err)
+		}
+
+		err = Transcode(strings.NewReader(input), &strings.Builder{}, transcodeSource{}, nil, toTranscodeTarget)
+		var marshalErr InvalidMarshalError
+		if !errors.As(err, &marshalErr) {
+			t.Errorf("Expected InvalidMarshalError, got %v", err)
+		}
+	})
+}
+
+func TestTranscodeBestEffort(t *testing.T) {
+	input := "Ann    30\nBob    XX\nCarl   40\nDan    YY\n"
+
+	var out strings.Builder
+	errs, err := TranscodeBestEffort(strings.NewReader(input), &out, transcodeSource{}, transcodeTarget{}, toTranscodeTarget)
+	if err != nil {
+		t.Fatalf("TranscodeBestEffort failed: %v", err)
+	}
+
+	if want := "030Ann   \n040Carl  \n"; out.String() != want {
+		t.Errorf("Expected %q, got %q", want, out.String())
+	}
+
+	if len(errs) != 2 {
+		t.Fatalf("Expected 2 errors, got %d: %v", len(errs), errs)
+	}
+
+	for _, line := range []int{1, 3} {
+		if !errors.Is(errs[line], ErrInvalidIntValue) {
+			t.Errorf("Expected ErrInvalidIntValue for line %d, got %v", line, errs[line])
+		}
+	}
+}
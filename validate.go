@@ -0,0 +1,70 @@
+package fixedlength
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+var ErrOutOfRange = errors.New("fixedlength: value out of range")
+
+// validateBounds checks that a numeric field's value lies within the
+// inclusive bounds given by the min and max tag options.
+func validateBounds(field reflect.Value, opts tagOptions) error {
+	minValue, hasMin := opts["min"]
+	maxValue, hasMax := opts["max"]
+	if !hasMin && !hasMax {
+		return nil
+	}
+
+	// compare returns -1, 0 or +1 depending on whether the field's
+	// value is less than, equal to or greater than bound.
+	var compare func(bound string) (int, error)
+
+	switch field.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		compare = func(bound string) (int, error) {
+			b, err := strconv.ParseInt(bound, 10, 64)
+			return cmp.Compare(field.Int(), b), err
+		}
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		compare = func(bound string) (int, error) {
+			b, err := strconv.ParseUint(bound, 10, 64)
+			return cmp.Compare(field.Uint(), b), err
+		}
+
+	case reflect.Float32, reflect.Float64:
+		compare = func(bound string) (int, error) {
+			b, err := strconv.ParseFloat(bound, 64)
+			return cmp.Compare(field.Float(), b), err
+		}
+
+	default:
+		return fmt.Errorf("%w: min/max on %s value", ErrTagInvalidOption, field.Kind())
+	}
+
+	if hasMin {
+		c, err := compare(minValue)
+		if err != nil {
+			return errors.Join(ErrTagInvalidOption, err)
+		}
+		if c < 0 {
+			return fmt.Errorf("%w: %v is less than min=%s", ErrOutOfRange, field.Interface(), minValue)
+		}
+	}
+
+	if hasMax {
+		c, err := compare(maxValue)
+		if err != nil {
+			return errors.Join(ErrTagInvalidOption, err)
+		}
+		if c > 0 {
+			return fmt.Errorf("%w: %v is greater than max=%s", ErrOutOfRange, field.Interface(), maxValue)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,153 @@
+package fixedlength
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValidateBounds(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   any
+		opts    tagOptions
+		wantErr error
+	}{
+		{
+			name:  "no bounds",
+			value: 42,
+			opts:  tagOptions{},
+		},
+		{
+			name:  "int within bounds",
+			value: 42,
+			opts:  tagOptions{"min": "0", "max": "42"},
+		},
+		{
+			name:    "int below min",
+			value:   -1,
+			opts:    tagOptions{"min": "0"},
+			wantErr: ErrOutOfRange,
+		},
+		{
+			name:    "int above max",
+			value:   int64(1000000),
+			opts:    tagOptions{"max": "999999"},
+			wantErr: ErrOutOfRange,
+		},
+		{
+			name:  "uint within bounds",
+			value: uint(5),
+			opts:  tagOptions{"min": "1", "max": "10"},
+		},
+		{
+			name:    "uint below min",
+			value:   uint8(0),
+			opts:    tagOptions{"min": "1"},
+			wantErr: ErrOutOfRange,
+		},
+		{
+			name:  "float within bounds",
+			value: 1550.85,
+			opts:  tagOptions{"min": "0.01", "max": "9999.99"},
+		},
+		{
+			name:    "float above max",
+			value:   float32(10.5),
+			opts:    tagOptions{"max": "10"},
+			wantErr: ErrOutOfRange,
+		},
+		{
+			name:    "invalid bound",
+			value:   42,
+			opts:    tagOptions{"min": "zero"},
+			wantErr: ErrTagInvalidOption,
+		},
+		{
+			name:    "bounds on string",
+			value:   "42",
+			opts:    tagOptions{"max": "50"},
+			wantErr: ErrTagInvalidOption,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateBounds(reflect.ValueOf(tt.value), tt.opts)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestUnmarshalBounds(t *testing.T) {
+	type testStruct struct {
+		Age    uint8   `range:"0,3,max=150"`
+		Income float64 `range:"3,-1,min=0"`
+	}
+
+	var v testStruct
+	if err := Unmarshal([]byte("0421550.85"), &v); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+
+	if v.Age != 42 {
+		t.Errorf("Expected v.Age to be 42, got %d", v.Age)
+	}
+
+	if v.Income != 1550.85 {
+		t.Errorf("Expected v.Income to be 1550.85, got %f", v.Income)
+	}
+
+	err := Unmarshal([]byte("200-1550.85"), &v)
+	if !errors.Is(err, ErrOutOfRange) {
+		t.Fatalf("Expected ErrOutOfRange, got %v", err)
+	}
+
+	if want := "field Age"; !strings.Contains(err.Error(), want) {
+		t.Errorf("Expected error to contain %q, got %q", want, err.Error())
+	}
+
+	// Values that overflow the field's type fail rather than wrapping around
+	overflows := []struct {
+		name    string
+		data    string
+		v       any
+		wantErr error
+	}{
+		{
+			name: "uint8",
+			data: "300",
+			v: &struct {
+				A uint8 `range:"0,3,max=255"`
+			}{},
+			wantErr: ErrInvalidUintValue,
+		},
+		{
+			name: "int8",
+			data: "300",
+			v: &struct {
+				A int8 `range:"0,3,max=100"`
+			}{},
+			wantErr: ErrInvalidIntValue,
+		},
+		{
+			name: "float32",
+			data: "1e50",
+			v: &struct {
+				A float32 `range:"0,4"`
+			}{},
+			wantErr: ErrInvalidFloatValue,
+		},
+	}
+
+	for _, tt := range overflows {
+		t.Run(tt.name+" overflow", func(t *testing.T) {
+			if err := Unmarshal([]byte(tt.data), tt.v); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}